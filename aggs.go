@@ -0,0 +1,213 @@
+package elastic
+
+import (
+    "encoding/json"
+    "sort"
+)
+
+// Aggregations is the "aggregations" object of a search response.
+// Accessors return zero values when the aggregation is missing
+type Aggregations map[string]interface{}
+
+// BucketsResult is a multi bucket aggregation: terms, date_histogram, histogram or range
+type BucketsResult struct {
+    DocCountErrorUpperBound int64
+    SumOtherDocCount int64
+    Buckets []Bucket
+}
+
+type Bucket struct {
+    // Key is a string for keyword terms and range buckets, float64 for numeric keys
+    Key interface{}
+    // KeyAsString is the formatted key of dates, or the exact numeric key
+    KeyAsString string
+    DocCount int64
+    // From/To bound range buckets, nil when open
+    From *float64
+    To *float64
+    // Aggs holds the sub aggregations of the bucket
+    Aggs Aggregations
+}
+
+// SingleBucketResult is a nested or reverse_nested aggregation
+type SingleBucketResult struct {
+    DocCount int64
+    Aggs Aggregations
+}
+
+type StatsResult struct {
+    Count int64
+    Min float64
+    Max float64
+    Avg float64
+    Sum float64
+}
+
+func (a Aggregations) get(name string) map[string]interface{} {
+    res, _ := a[name].(map[string]interface{})
+    return res
+}
+
+func (a Aggregations) Terms(name string) BucketsResult {
+    return a.buckets(name)
+}
+
+func (a Aggregations) DateHistogram(name string) BucketsResult {
+    return a.buckets(name)
+}
+
+func (a Aggregations) Histogram(name string) BucketsResult {
+    return a.buckets(name)
+}
+
+func (a Aggregations) Range(name string) BucketsResult {
+    return a.buckets(name)
+}
+
+func (a Aggregations) buckets(name string) BucketsResult {
+    var res BucketsResult
+
+    body := a.get(name)
+    res.DocCountErrorUpperBound = int64(toInt(body["doc_count_error_upper_bound"]))
+    res.SumOtherDocCount = int64(toInt(body["sum_other_doc_count"]))
+
+    switch buckets := body["buckets"].(type) {
+    case []interface{}:
+        for _, item := range buckets {
+            if bucket, ok := item.(map[string]interface{}); ok {
+                res.Buckets = append(res.Buckets, parseBucket(bucket["key"], bucket))
+            }
+        }
+    case map[string]interface{}:
+        // "keyed": true responses, sorted by key for a stable order
+        keys := make([]string, 0, len(buckets))
+        for key := range buckets {
+            keys = append(keys, key)
+        }
+        sort.Strings(keys)
+
+        for _, key := range keys {
+            if bucket, ok := buckets[key].(map[string]interface{}); ok {
+                res.Buckets = append(res.Buckets, parseBucket(key, bucket))
+            }
+        }
+    }
+
+    return res
+}
+
+func parseBucket(key interface{}, bucket map[string]interface{}) Bucket {
+    res := Bucket{
+        Key: key,
+        DocCount: int64(toInt(bucket["doc_count"])),
+        From: toFloatPtr(bucket["from"]),
+        To: toFloatPtr(bucket["to"]),
+        Aggs: Aggregations(bucket),
+    }
+    res.KeyAsString, _ = bucket["key_as_string"].(string)
+
+    if number, ok := key.(json.Number); ok {
+        if res.KeyAsString == "" {
+            res.KeyAsString = number.String()
+        }
+        res.Key, _ = number.Float64()
+    }
+
+    return res
+}
+
+// value reads a single value metric, false when it is missing or null
+func (a Aggregations) value(name string) (float64, bool) {
+    value := toFloatPtr(a.get(name)["value"])
+    if value == nil {
+        return 0, false
+    }
+
+    return *value, true
+}
+
+func (a Aggregations) Avg(name string) (float64, bool) {
+    return a.value(name)
+}
+
+func (a Aggregations) Sum(name string) (float64, bool) {
+    return a.value(name)
+}
+
+func (a Aggregations) Min(name string) (float64, bool) {
+    return a.value(name)
+}
+
+func (a Aggregations) Max(name string) (float64, bool) {
+    return a.value(name)
+}
+
+func (a Aggregations) Cardinality(name string) (int64, bool) {
+    value, ok := a.value(name)
+    return int64(value), ok
+}
+
+// Stats leaves Min, Max and Avg at zero when Count is zero
+func (a Aggregations) Stats(name string) StatsResult {
+    body := a.get(name)
+
+    res := StatsResult{Count: int64(toInt(body["count"]))}
+    for field, target := range map[string]*float64{"min": &res.Min, "max": &res.Max, "avg": &res.Avg, "sum": &res.Sum} {
+        if value := toFloatPtr(body[field]); value != nil {
+            *target = *value
+        }
+    }
+
+    return res
+}
+
+// Percentiles maps the percent( e.g. "95.0" ) to its value, skipping empty ones
+func (a Aggregations) Percentiles(name string) map[string]float64 {
+    res := map[string]float64{}
+
+    switch values := a.get(name)["values"].(type) {
+    case map[string]interface{}:
+        for percent, value := range values {
+            if v := toFloatPtr(value); v != nil {
+                res[percent] = *v
+            }
+        }
+    case []interface{}:
+        // "keyed": false responses
+        for _, item := range values {
+            entry, _ := item.(map[string]interface{})
+            key, _ := entry["key"].(json.Number)
+            if v := toFloatPtr(entry["value"]); v != nil {
+                res[key.String()] = *v
+            }
+        }
+    }
+
+    return res
+}
+
+func (a Aggregations) TopHits(name string) (InnerHits, error) {
+    hits, _ := a.get(name)["hits"].(map[string]interface{})
+    if hits == nil {
+        return InnerHits{}, nil
+    }
+
+    return parseHits(hits)
+}
+
+func (a Aggregations) Nested(name string) SingleBucketResult {
+    return a.singleBucket(name)
+}
+
+func (a Aggregations) ReverseNested(name string) SingleBucketResult {
+    return a.singleBucket(name)
+}
+
+func (a Aggregations) singleBucket(name string) SingleBucketResult {
+    body := a.get(name)
+
+    return SingleBucketResult{
+        DocCount: int64(toInt(body["doc_count"])),
+        Aggs: Aggregations(body),
+    }
+}
@@ -0,0 +1,43 @@
+package elastic
+
+import (
+    "context"
+    "encoding/base64"
+    "fmt"
+)
+
+// authorization returns the Authorization header value for a request.
+// CredentialsProvider wins over APIKey, which wins over the tokens and User/Password
+func (c *Client) authorization(ctx context.Context) (string, error) {
+    c.mu.RLock()
+    config := c.config
+    c.mu.RUnlock()
+
+    if config.CredentialsProvider != nil {
+        auth, err := config.CredentialsProvider(ctx)
+        if err != nil {
+            return "", fmt.Errorf("Failed to get elastic credentials: %w", err)
+        }
+
+        return auth, nil
+    }
+
+    if config.APIKey != "" {
+        return "ApiKey " + config.APIKey, nil
+    }
+
+    if config.BearerToken != "" {
+        return "Bearer " + config.BearerToken, nil
+    }
+
+    if config.ServiceToken != "" {
+        return "Bearer " + config.ServiceToken, nil
+    }
+
+    if config.User != "" {
+        credentials := config.User + ":" + config.Password
+        return "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials)), nil
+    }
+
+    return "", nil
+}
@@ -0,0 +1,242 @@
+package elastic
+
+import (
+    "errors"
+    "sync"
+    "sync/atomic"
+    "time"
+)
+
+var ErrBulkProcessorClosed = errors.New("Bulk processor is closed")
+
+// BulkProcessorConfig configures when a BulkProcessor flushes its batches.
+// Zero values fall back to the Default* settings
+type BulkProcessorConfig struct {
+    // Index is where every item is written
+    Index string
+    // Workers is the number of batches sent in parallel, 1 when zero
+    Workers int
+    // BulkActions flushes after this many items, DefaultBulkActions when zero
+    BulkActions int
+    // BulkSize flushes before the batch grows over this many bytes, DefaultBulkSize when zero
+    BulkSize int
+    // FlushInterval flushes the pending items periodically, disabled when zero
+    FlushInterval time.Duration
+    WaitToRefresh bool
+
+    // Before and After are called from the workers, possibly concurrently
+    Before func(batchId int64, actions int)
+    After func(batchId int64, result SetResult)
+}
+
+// BulkProcessor batches items into _bulk requests sent by a pool of workers.
+// It is safe for concurrent use
+type BulkProcessor struct {
+    doc *doc
+    config BulkProcessorConfig
+
+    // mu guards pending, pendingBytes and closed
+    mu sync.Mutex
+    pending []string
+    pendingBytes int
+    closed bool
+
+    // inflightMu guards inflight, the number of dispatched but unfinished batches
+    inflightMu sync.Mutex
+    inflightDone *sync.Cond
+    inflight int
+
+    batches chan []string
+    batchId int64
+    workers sync.WaitGroup
+    stop chan struct{}
+    ticker sync.WaitGroup
+}
+
+// BulkProcessor starts a processor writing to config.Index,
+// it must be closed to send the pending items and stop the workers
+func (i *doc) BulkProcessor(config BulkProcessorConfig) *BulkProcessor {
+    if config.Workers <= 0 {
+        config.Workers = 1
+    }
+    if config.BulkActions <= 0 {
+        config.BulkActions = DefaultBulkActions
+    }
+    if config.BulkSize <= 0 {
+        config.BulkSize = DefaultBulkSize
+    }
+
+    p := &BulkProcessor{
+        doc: i,
+        config: config,
+        batches: make(chan []string, config.Workers),
+        stop: make(chan struct{}),
+    }
+    p.inflightDone = sync.NewCond(&p.inflightMu)
+
+    p.workers.Add(config.Workers)
+    for w := 0; w < config.Workers; w++ {
+        go p.work()
+    }
+
+    if config.FlushInterval > 0 {
+        p.ticker.Add(1)
+        go p.tick()
+    }
+
+    return p
+}
+
+// Add queues a create of the entity
+func (p *BulkProcessor) Add(entity map[string]interface{}) error {
+    return p.add(getAddStmts, entity)
+}
+
+// Update queues a partial update, the entity must have an _id
+func (p *BulkProcessor) Update(entity map[string]interface{}) error {
+    return p.add(getUpdateStmts, entity)
+}
+
+// Delete queues a delete, the entity must have an _id
+func (p *BulkProcessor) Delete(entity map[string]interface{}) error {
+    return p.add(getDeleteStmts, entity)
+}
+
+// Index queues a create-or-replace of the entity
+func (p *BulkProcessor) Index(entity map[string]interface{}) error {
+    return p.add(getIndexStmts, entity)
+}
+
+func (p *BulkProcessor) add(getStmts func([]map[string]interface{}, string) ([]string, error), entity map[string]interface{}) error {
+    stmts, err := getStmts([]map[string]interface{}{entity}, p.config.Index)
+    if err != nil {
+        return err
+    }
+    stmt := stmts[0]
+
+    p.mu.Lock()
+    defer p.mu.Unlock()
+
+    if p.closed {
+        return ErrBulkProcessorClosed
+    }
+
+    if len(p.pending) > 0 && p.pendingBytes + len(stmt) > p.config.BulkSize {
+        p.dispatch()
+    }
+
+    p.pending = append(p.pending, stmt)
+    p.pendingBytes += len(stmt)
+
+    if len(p.pending) >= p.config.BulkActions || p.pendingBytes >= p.config.BulkSize {
+        p.dispatch()
+    }
+
+    return nil
+}
+
+// dispatch hands the pending items to the workers, p.mu must be held.
+// It blocks while every worker is busy, which slows the producers down
+func (p *BulkProcessor) dispatch() {
+    if len(p.pending) == 0 {
+        return
+    }
+
+    p.inflightMu.Lock()
+    p.inflight++
+    p.inflightMu.Unlock()
+
+    p.batches <- p.pending
+    p.pending = nil
+    p.pendingBytes = 0
+}
+
+func (p *BulkProcessor) work() {
+    defer p.workers.Done()
+
+    for stmts := range p.batches {
+        batchId := atomic.AddInt64(&p.batchId, 1)
+        if p.config.Before != nil {
+            p.config.Before(batchId, len(stmts))
+        }
+
+        origins := make([]setOrigin, len(stmts))
+        for k := range stmts {
+            origins[k] = setOrigin{"", k}
+        }
+
+        result := p.doc.bulk(stmts, origins, p.config.WaitToRefresh)
+        if p.config.After != nil {
+            p.config.After(batchId, result)
+        }
+
+        p.inflightMu.Lock()
+        p.inflight--
+        p.inflightDone.Broadcast()
+        p.inflightMu.Unlock()
+    }
+}
+
+func (p *BulkProcessor) tick() {
+    defer p.ticker.Done()
+
+    t := time.NewTicker(p.config.FlushInterval)
+    defer t.Stop()
+
+    for {
+        select {
+        case <-t.C:
+            p.mu.Lock()
+            if !p.closed {
+                p.dispatch()
+            }
+            p.mu.Unlock()
+        case <-p.stop:
+            return
+        }
+    }
+}
+
+// Flush sends the pending items and waits until every dispatched batch is done
+func (p *BulkProcessor) Flush() error {
+    p.mu.Lock()
+    if p.closed {
+        p.mu.Unlock()
+        return ErrBulkProcessorClosed
+    }
+    p.dispatch()
+    p.mu.Unlock()
+
+    p.wait()
+
+    return p.doc.ctx.Err()
+}
+
+func (p *BulkProcessor) wait() {
+    p.inflightMu.Lock()
+    for p.inflight > 0 {
+        p.inflightDone.Wait()
+    }
+    p.inflightMu.Unlock()
+}
+
+// Close sends the pending items, waits for every batch and stops the workers.
+// It returns the docs context error when the context ended before the items were sent
+func (p *BulkProcessor) Close() error {
+    p.mu.Lock()
+    if p.closed {
+        p.mu.Unlock()
+        return nil
+    }
+    p.closed = true
+    p.dispatch()
+    p.mu.Unlock()
+
+    close(p.stop)
+    p.ticker.Wait()
+
+    close(p.batches)
+    p.workers.Wait()
+
+    return p.doc.ctx.Err()
+}
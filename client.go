@@ -0,0 +1,96 @@
+package elastic
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net/http"
+)
+
+// NewClient creates a standalone client, so a single process can work
+// with several clusters at once
+func NewClient(config Config) (*Client, error) {
+    c := &Client{}
+
+    err := c.init(config)
+    if err != nil {
+        return nil, err
+    }
+
+    return c, nil
+}
+
+func (c *Client) init(config Config) error {
+    addresses := nodeAddresses(config)
+    if len(addresses) == 0 {
+        return errors.New("Elastic host is not set")
+    }
+
+    if config.Scheme != "" && config.Scheme != "http" && config.Scheme != "https" {
+        return errors.New(fmt.Sprintf("Unsupported elastic scheme: %s", config.Scheme))
+    }
+
+    httpClient, err := newHttpClient(config)
+    if err != nil {
+        return err
+    }
+
+    c.mu.Lock()
+    c.config = config
+    c.nodes = newNodes(config, addresses)
+    c.nodeIdx = 0
+    c.httpClient = httpClient
+    c.mu.Unlock()
+
+    if config.DiscoverNodes {
+        return c.DiscoverNodes()
+    }
+
+    return nil
+}
+
+func newHttpClient(config Config) (*http.Client, error) {
+    if config.HTTPClient != nil {
+        if hasTlsConfig(config) {
+            return nil, errors.New("Elastic TLS settings can't be combined with HTTPClient")
+        }
+
+        return config.HTTPClient, nil
+    }
+
+    if config.Transport == nil && !hasTlsConfig(config) {
+        return http.DefaultClient, nil
+    }
+
+    transport, err := newTlsTransport(config, config.Transport)
+    if err != nil {
+        return nil, err
+    }
+
+    return &http.Client{Transport: transport}, nil
+}
+
+// IsInitiated reports whether the client has been configured with a host
+func (c *Client) IsInitiated() bool {
+    c.mu.RLock()
+    defer c.mu.RUnlock()
+
+    return len(c.nodes) > 0
+}
+
+func (c *Client) getHttpClient() *http.Client {
+    c.mu.RLock()
+    defer c.mu.RUnlock()
+
+    return c.httpClient
+}
+
+// Docs returns the documents API bound to this client
+func (c *Client) Docs() Doc {
+    return &doc{c, context.Background()}
+}
+
+// Indexes returns the indexes API bound to this client
+func (c *Client) Indexes() Index {
+    return &index{c, context.Background()}
+}
@@ -1,40 +1,98 @@
 package elastic
 
 import (
+    "context"
     "fmt"
     "errors"
+    "net/url"
+    "strconv"
+    "strings"
 )
 
 type Doc interface {
     Get(entityId string, indexName string) (map[string]interface{}, error)
 
-    MGet(entityIds []string, indexName string) ([]map[string]interface{}, error)
+    GetDocument(entityId string, indexName string, opts ...GetOptions) (*Document, error)
 
-    Search(query map[string]interface{}, indexName string) ([]interface{}, int, error)
+    Exists(entityId string, indexName string, opts ...GetOptions) (bool, error)
+
+    Source(entityId string, indexName string, opts ...GetOptions) (map[string]interface{}, error)
+
+    MGet(entityIds []string, indexName string, opts ...MGetOptions) ([]MGetResult, error)
+
+    MGetItems(items []MGetItem, indexName string, opts ...MGetOptions) ([]MGetResult, error)
+
+    Search(query map[string]interface{}, indexName string) (SearchResult, error)
+
+    MSearch(requests []SearchRequest) ([]MSearchResult, error)
+
+    Scroll(query map[string]interface{}, indexName string, keepAlive string, pageSize int) *ScrollIterator
+
+    Paginate(query map[string]interface{}, indexName string, keepAlive string, pageSize int, cursor ...string) *Paginator
 
     Create(entity map[string]interface{}, indexName string, waitToRefresh ...bool) (string, error)
+
+    Index(entity map[string]interface{}, indexName string, waitToRefresh ...bool) (string, error)
     
     Update(entity map[string]interface{}, indexName string, waitToRefresh ...bool) (string, error)
-    
+
+    UpdateWithOptions(entity map[string]interface{}, indexName string, opts WriteOptions) (WriteResult, error)
+
     Delete(entity map[string]interface{}, indexName string, waitToRefresh ...bool) (string, error)
-    
+
+    DeleteWithOptions(entity map[string]interface{}, indexName string, opts WriteOptions) (WriteResult, error)
+
+    Patch(entity map[string]interface{}, indexName string, opts ...PatchOptions) (PatchResult, error)
+
     Set(entities SetParams, indexName string, waitToRefresh ...bool) SetResult
+
+    BulkProcessor(config BulkProcessorConfig) *BulkProcessor
+
+    WithContext(ctx context.Context) Doc
 }
 
-type doc struct {}
+type doc struct {
+    client *Client
+    ctx context.Context
+}
+
+// WithContext returns a copy of the docs API whose requests are bound to ctx
+func (i *doc) WithContext(ctx context.Context) Doc {
+    return &doc{i.client, ctx}
+}
 
 func (i *doc) Get(entityId string, indexName string) (map[string]interface{}, error) {
+    document, err := i.GetDocument(entityId, indexName)
+    if err != nil || document == nil {
+        return nil, err
+    }
+
+    return document.Source, nil
+}
+
+// GetDocument fetches the document with its metadata, nil when it doesn't exist
+func (i *doc) GetDocument(entityId string, indexName string, opts ...GetOptions) (*Document, error) {
     if len(entityId) == 0 {
         return nil, errors.New("No entity id transmitted")
     }
 
-    res, err := Request(MethodGet, "/"+indexName+"/_doc/"+entityId)
+    var opt GetOptions
+    if len(opts) > 0 {
+        opt = opts[0]
+    }
+
+    res, err := i.client.RequestCtx(i.ctx, MethodGet, "/"+indexName+"/_doc/"+url.PathEscape(entityId)+opt.query(), "")
     if err != nil {
-        return nil, errors.New(fmt.Sprintf("Failed to get elastic entity: %v", err))
+        // a missing document is not an error, a missing index is
+        if errors.Is(err, ErrNotFound) && !errors.Is(err, ErrIndexNotFound) {
+            return nil, nil
+        }
+
+        return nil, fmt.Errorf("Failed to get elastic entity: %w", err)
     }
 
-    errStr, ok := res["error"].(string); if ok {
-        return nil, errors.New(errStr)
+    elErr := parseError(res); if elErr != nil {
+        return nil, elErr
     }
 
     found, ok := res["found"].(bool); if !ok {
@@ -45,170 +103,581 @@ func (i *doc) Get(entityId string, indexName string) (map[string]interface{}, er
         return nil, nil
     }
 
-    return res["_source"].(map[string]interface{}), nil
+    document, err := parseDocument(res)
+    if err != nil {
+        return nil, err
+    }
+
+    return &document, nil
 }
 
-func (i *doc) MGet(entityIds []string, indexName string) ([]map[string]interface{}, error) {
-    lenEntityIds := len(entityIds)
-    for i := lenEntityIds - 1; i >= 0; i-- {
-        if len(entityId) == 0 {
-            entityIds = append(entityIds[:i], entityIds[i+1:]...)
+// Exists checks the document through HEAD without reading it, a missing index is reported as false too
+func (i *doc) Exists(entityId string, indexName string, opts ...GetOptions) (bool, error) {
+    if len(entityId) == 0 {
+        return false, errors.New("No entity id transmitted")
+    }
+
+    var opt GetOptions
+    if len(opts) > 0 {
+        opt = opts[0]
+    }
+
+    _, status, err := i.client.request(i.ctx, MethodHead, "/"+indexName+"/_doc/"+url.PathEscape(entityId)+opt.query(), "")
+    if err != nil {
+        if status == 404 {
+            return false, nil
+        }
+
+        return false, fmt.Errorf("Failed to check elastic entity: %w", err)
+    }
+
+    return status == 200, nil
+}
+
+// Source fetches only the _source of the document, nil when it doesn't exist.
+// StoredFields of opts are not supported by the endpoint
+func (i *doc) Source(entityId string, indexName string, opts ...GetOptions) (map[string]interface{}, error) {
+    if len(entityId) == 0 {
+        return nil, errors.New("No entity id transmitted")
+    }
+
+    var opt GetOptions
+    if len(opts) > 0 {
+        opt = opts[0]
+        opt.StoredFields = nil
+    }
+
+    res, err := i.client.RequestCtx(i.ctx, MethodGet, "/"+indexName+"/_source/"+url.PathEscape(entityId)+opt.query(), "")
+    if err != nil {
+        // a missing document is not an error, a missing index is
+        if errors.Is(err, ErrNotFound) && !errors.Is(err, ErrIndexNotFound) {
+            return nil, nil
+        }
+
+        return nil, fmt.Errorf("Failed to get elastic entity source: %w", err)
+    }
+
+    return res, nil
+}
+
+// parseDocument reads the metadata and the source of a found GET/MGET document
+func parseDocument(raw map[string]interface{}) (Document, error) {
+    document := Document{raw: raw}
+    document.Index, _ = raw["_index"].(string)
+    document.ID, _ = raw["_id"].(string)
+    document.Routing, _ = raw["_routing"].(string)
+    document.Version = int64(toInt(raw["_version"]))
+    document.SeqNo = int64(toInt(raw["_seq_no"]))
+    document.PrimaryTerm = int64(toInt(raw["_primary_term"]))
+    document.Fields, _ = raw["fields"].(map[string]interface{})
+
+    // _source is missing when it is disabled or filtered out completely
+    if source, ok := raw["_source"]; ok {
+        document.Source, ok = source.(map[string]interface{}); if !ok {
+            return document, errors.New(fmt.Sprintf("Unknown _source of elastic entity [%s]: %v", document.ID, source))
         }
     }
+
+    return document, nil
+}
+
+// MGet fetches the documents in the order of entityIds, with Found
+// set to false for the missing ones
+func (i *doc) MGet(entityIds []string, indexName string, opts ...MGetOptions) ([]MGetResult, error) {
     if len(entityIds) == 0 {
         return nil, errors.New("No entity ids transmitted")
     }
 
-    params, err := toJson([string]string{}{
-        "ids": entityIds,
-    })
+    for pos, entityId := range entityIds {
+        if len(entityId) == 0 {
+            return nil, errors.New(fmt.Sprintf("No entity id transmitted at position %d", pos))
+        }
+    }
+
+    return i.mget(map[string]interface{}{"ids": entityIds}, len(entityIds), indexName, opts...)
+}
+
+// MGetItems fetches documents from several indices in a single request.
+// Items without Index are read from indexName
+func (i *doc) MGetItems(items []MGetItem, indexName string, opts ...MGetOptions) ([]MGetResult, error) {
+    if len(items) == 0 {
+        return nil, errors.New("No entity ids transmitted")
+    }
+
+    docs := make([]interface{}, 0, len(items))
+    for pos, item := range items {
+        if len(item.ID) == 0 {
+            return nil, errors.New(fmt.Sprintf("No entity id transmitted at position %d", pos))
+        }
+
+        index := item.Index
+        if index == "" {
+            index = indexName
+        }
+        if index == "" {
+            return nil, errors.New(fmt.Sprintf("No index transmitted for entity [%s]", item.ID))
+        }
+
+        d := map[string]interface{}{"_index": index, "_id": item.ID}
+        if item.Routing != "" {
+            d["routing"] = item.Routing
+        }
+        docs = append(docs, d)
+    }
+
+    return i.mget(map[string]interface{}{"docs": docs}, len(items), "", opts...)
+}
+
+func (i *doc) mget(body map[string]interface{}, count int, indexName string, opts ...MGetOptions) ([]MGetResult, error) {
+    params, err := toJson(body)
     if err != nil {
         return nil, errors.New(fmt.Sprintf("Failed to json elastic entity ids: %v", err))
     }
 
-    res, err := Request(MethodGet, "/"+indexName+"/_mget", params)
+    endpoint := "/_mget"
+    if indexName != "" {
+        endpoint = "/"+indexName+endpoint
+    }
+    if len(opts) > 0 {
+        endpoint += opts[0].query()
+    }
+
+    res, err := i.client.RequestCtx(i.ctx, MethodGet, endpoint, params)
     if err != nil {
-        return nil, errors.New(fmt.Sprintf("Failed to get elastic entities: %v", err))
+        return nil, fmt.Errorf("Failed to get elastic entities: %w", err)
     }
 
     elErr := parseError(res); if elErr != nil {
         return nil, elErr
     }
 
-    docs, ok := res["docs"].(map[string]interface{}); if !ok {
+    docs, ok := res["docs"].([]interface{}); if !ok || len(docs) != count {
         return nil, errors.New(fmt.Sprintf("Unknown error at doc.MGet: %v", res))
     }
 
-    found, ok := docs["found"].(bool); if !ok {
-        return nil, errors.New(fmt.Sprintf("Unknown error at doc.Get: %v", res))
+    results := make([]MGetResult, 0, len(docs))
+    for _, item := range docs {
+        results = append(results, parseMGetDoc(item))
     }
-    res = nil
 
-    if !found {
-        return make([]map[string]interface{}), nil
+    return results, nil
+}
+
+// parseMGetDoc reports a failure of a single document in its Err
+func parseMGetDoc(item interface{}) MGetResult {
+    raw, ok := item.(map[string]interface{}); if !ok {
+        return MGetResult{Err: errors.New(fmt.Sprintf("Unknown mget document: %v", item))}
+    }
+
+    res := MGetResult{}
+    res.raw = raw
+    res.Index, _ = raw["_index"].(string)
+    res.ID, _ = raw["_id"].(string)
+
+    if elErr, ok := raw["error"]; ok {
+        res.Err = newElasticError(0, elErr)
+        return res
+    }
+
+    res.Found, _ = raw["found"].(bool)
+    if !res.Found {
+        return res
     }
 
-    return res["_source"].([]map[string]interface{}), nil
+    res.Document, res.Err = parseDocument(raw)
+
+    return res
 }
 
+// Create creates the entity with its _id through the _create endpoint, failing with
+// ErrDocumentExists when it already exists. The _id is generated when missing
 func (i *doc) Create(entity map[string]interface{}, indexName string, waitToRefresh ...bool) (string, error) {
     action := "create"
 
-    delete(entity, "_id")
-    var entId string
-
-    entJson, err := toJson(entity)
+    entId, values, entJson, err := splitEntity(ActionCreate, entity, indexName, false)
     if err != nil {
-        return entId, errors.New(fmt.Sprintf("Failed to json elastic entity: %v", err))
+        return "", err
+    }
+
+    method, endpoint := MethodPost, "/"+indexName+"/_doc"
+    if entId != "" {
+        method, endpoint = MethodPut, "/"+indexName+"/_create/"+url.PathEscape(entId)
     }
 
-    endpoint := "/"+indexName+"/_doc"
-    result, err := Request(MethodPost, endpoint, entJson, waitToRefresh...)
+    result, err := i.client.RequestCtx(i.ctx, method, endpoint+encodeQuery(values), entJson, waitToRefresh...)
     if err != nil {
-        return entId, errors.New(fmt.Sprintf("Failed to %s elastic entity: %v", action, err))
+        var elErr *ElasticError
+        if errors.As(err, &elErr) {
+            elErr.create = true
+        }
+
+        return entId, fmt.Errorf("Failed to %s elastic entity: %w", action, err)
     }
 
     return parseEditItemResponse(result, action)
 }
 
+// Index creates the entity or replaces it when its _id exists, the _id is generated when missing
+func (i *doc) Index(entity map[string]interface{}, indexName string, waitToRefresh ...bool) (string, error) {
+    action := "index"
+
+    entId, values, entJson, err := splitEntity(ActionIndex, entity, indexName, false)
+    if err != nil {
+        return "", err
+    }
+
+    method, endpoint := MethodPost, "/"+indexName+"/_doc"
+    if entId != "" {
+        method, endpoint = MethodPut, "/"+indexName+"/_doc/"+url.PathEscape(entId)
+    }
+
+    result, err := i.client.RequestCtx(i.ctx, method, endpoint+encodeQuery(values), entJson, waitToRefresh...)
+    if err != nil {
+        return entId, fmt.Errorf("Failed to %s elastic entity: %w", action, err)
+    }
+
+    res, err := parseWriteResponse(result, action, "created", "updated")
+    return res.ID, err
+}
+
+// splitEntity splits the entity like a bulk item of action: the _id, its meta keys
+// ( _routing, _if_seq_no, ... ) as query values and the json of the other fields
+func splitEntity(action Action, entity map[string]interface{}, indexName string, requireId bool) (string, url.Values, string, error) {
+    meta, source, err := splitBulkEntity(action, entity, indexName, true, requireId)
+    if err != nil {
+        return "", nil, "", err
+    }
+    entId, _ := meta["_id"].(string)
+
+    entJson, err := toJson(source)
+    if err != nil {
+        return entId, nil, "", errors.New(fmt.Sprintf("Failed to json elastic entity: %v", err))
+    }
+
+    return entId, metaValues(meta), entJson, nil
+}
+
+// metaValues returns the bulk meta of an entity as query values
+func metaValues(meta map[string]interface{}) url.Values {
+    values := url.Values{}
+    for field, value := range meta {
+        if field != "_index" && field != "_id" {
+            values.Set(field, fmt.Sprint(value))
+        }
+    }
+
+    return values
+}
+
+func encodeQuery(values url.Values) string {
+    if len(values) == 0 {
+        return ""
+    }
+
+    return "?" + values.Encode()
+}
+
 func (i *doc) Update(entity map[string]interface{}, indexName string, waitToRefresh ...bool) (string, error) {
+    res, err := i.UpdateWithOptions(entity, indexName, WriteOptions{WaitToRefresh: len(waitToRefresh) > 0 && waitToRefresh[0]})
+    return res.ID, err
+}
+
+// UpdateWithOptions replaces the entity, conditionally when opts has a seq_no or an external version.
+// The meta keys of the entity are sent like the ones of a SetParams.ToIndex item
+func (i *doc) UpdateWithOptions(entity map[string]interface{}, indexName string, opts WriteOptions) (WriteResult, error) {
     action := "update"
 
-    entId, ok := entity["_id"].(string); if !ok {
-        return "", errors.New(fmt.Sprintf("No _id transmitted for %s stmt: %v", action, entity))
+    // a full replace is an index of an existing _id
+    entId, values, entJson, err := splitEntity(ActionIndex, entity, indexName, true)
+    if err != nil {
+        return WriteResult{ID: entId}, err
+    }
+
+    endpoint := "/"+indexName+"/_doc/"+url.PathEscape(entId)+opts.query(values)
+    result, err := i.client.RequestCtx(i.ctx, MethodPut, endpoint, entJson, opts.WaitToRefresh)
+    if err != nil {
+        return WriteResult{ID: entId}, fmt.Errorf("Failed to %s elastic entity: %w", action, err)
+    }
+
+    return parseWriteResponse(result, action)
+}
+
+// Patch partially updates the entity through the _update endpoint. The entity takes the same
+// keys as a SetParams.ToUpdate item: _id, the fields to change or a _script, _upsert,
+// _routing, _if_seq_no, _if_primary_term and _retry_on_conflict
+func (i *doc) Patch(entity map[string]interface{}, indexName string, opts ...PatchOptions) (PatchResult, error) {
+    action := "update"
+
+    var opt PatchOptions
+    if len(opts) > 0 {
+        opt = opts[0]
     }
-    delete(entity, "_id")
 
-    entJson, err := toJson(entity)
+    meta, source, err := splitBulkEntity(ActionUpdate, entity, indexName, true, true)
     if err != nil {
-        return entId, errors.New(fmt.Sprintf("Failed to json elastic entity: %v", err))
+        return PatchResult{}, err
     }
+    entId := meta["_id"].(string)
 
-    endpoint := "/"+indexName+"/_doc/"+entId
-    result, err := Request(MethodPut, endpoint, entJson, waitToRefresh...)
+    body, err := updateBody(entity, source, opt.DocAsUpsert)
     if err != nil {
-        return entId, errors.New(fmt.Sprintf("Failed to %s elastic entity: %v", action, err))
+        return PatchResult{}, err
+    }
+    if opt.DisableDetectNoop {
+        body["detect_noop"] = false
     }
 
-    return parseEditItemResponse(result, action)
+    bodyJson, err := toJson(body)
+    if err != nil {
+        return PatchResult{}, errors.New(fmt.Sprintf("Failed to json elastic entity: %v", err))
+    }
+
+    endpoint := "/"+indexName+"/_update/"+url.PathEscape(entId)+opt.query(meta)
+    result, err := i.client.RequestCtx(i.ctx, MethodPost, endpoint, bodyJson, opt.WaitToRefresh)
+    if err != nil {
+        return PatchResult{WriteResult: WriteResult{ID: entId}}, fmt.Errorf("Failed to %s elastic entity: %w", action, err)
+    }
+
+    res, err := parseWriteResponse(result, action, "updated", "created", "noop")
+    if err != nil {
+        return PatchResult{WriteResult: res}, err
+    }
+
+    get, _ := result["get"].(map[string]interface{})
+    patched := PatchResult{WriteResult: res}
+    patched.Source, _ = get["_source"].(map[string]interface{})
+
+    return patched, nil
 }
 
 func (i *doc) Delete(entity map[string]interface{}, indexName string, waitToRefresh ...bool) (string, error) {
+    res, err := i.DeleteWithOptions(entity, indexName, WriteOptions{WaitToRefresh: len(waitToRefresh) > 0 && waitToRefresh[0]})
+    return res.ID, err
+}
+
+// DeleteWithOptions deletes the entity, conditionally when opts has a seq_no or an external version
+func (i *doc) DeleteWithOptions(entity map[string]interface{}, indexName string, opts WriteOptions) (WriteResult, error) {
     action := "delete"
 
-    entId, ok := entity["_id"].(string); if !ok {
-        return "", errors.New(fmt.Sprintf("No _id transmitted for %s stmt: %v", action, entity))
+    entId, values, _, err := splitEntity(ActionDelete, entity, indexName, true)
+    if err != nil {
+        return WriteResult{ID: entId}, err
     }
-    delete(entity, "_id")
 
-    entJson, err := toJson(entity)
+    endpoint := "/"+indexName+"/_doc/"+url.PathEscape(entId)+opts.query(values)
+    result, err := i.client.RequestCtx(i.ctx, MethodDelete, endpoint, "", opts.WaitToRefresh)
     if err != nil {
-        return entId, errors.New(fmt.Sprintf("Failed to json elastic entity: %v", err))
+        return WriteResult{ID: entId}, fmt.Errorf("Failed to %s elastic entity: %w", action, err)
     }
 
-    endpoint := "/"+indexName+"/_doc/"+entId
-    result, err := Request(MethodDelete, endpoint, entJson, waitToRefresh...)
+    return parseWriteResponse(result, action)
+}
+
+func (i *doc) Search(query map[string]interface{}, indexName string) (SearchResult, error) {
+    result, err := i.search(query, indexName)
     if err != nil {
-        return entId, errors.New(fmt.Sprintf("Failed to %s elastic entity: %v", action, err))
+        return SearchResult{}, err
     }
 
-    return parseEditItemResponse(result, action)
+    return parseSearchResult(result)
 }
 
-func (i *doc) Search(query map[string]interface{}, indexName string) ([]interface{}, int, error) {
-    var entities []interface{}
-    var totalFound int
+// MSearch runs several searches in a single request. The error is only set
+// when the whole request failed, a failed search sets Err of its own result
+func (i *doc) MSearch(requests []SearchRequest) ([]MSearchResult, error) {
+    if len(requests) == 0 {
+        return nil, errors.New("No search requests transmitted")
+    }
+
+    stmts, err := getMSearchStmts(requests)
+    if err != nil {
+        return nil, err
+    }
+
+    result, err := i.client.RequestCtx(i.ctx, MethodGet, "/_msearch", strings.Join(stmts, ""))
+    if err != nil {
+        return nil, fmt.Errorf("Failed to msearch elastic: %w", err)
+    }
 
-    if !IsInitiated() {
-        return entities, totalFound, errors.New("Elastic is not initiated")
+    return parseMSearchResponse(result, len(requests))
+}
+
+// search returns the raw _search response
+func (i *doc) search(query map[string]interface{}, indexName string) (map[string]interface{}, error) {
+    if !i.client.IsInitiated() {
+        return nil, ErrNotInitiated
     }
 
 	queryJson, err := toJson(query)
 	if err != nil {
-		return entities, totalFound, err
+		return nil, err
 	}
 
     endpoint := "/"+indexName+"/_search"
-	result, err := Request(MethodGet, endpoint, queryJson)
+	result, err := i.client.RequestCtx(i.ctx, MethodGet, endpoint, queryJson)
 	if err != nil {
-		return entities, totalFound, err
+		return nil, err
 	}
 
     elErr := parseError(result); if elErr != nil {
-        return entities, totalFound, elErr
+        return nil, elErr
     }
 
-    hits, ok := result["hits"].(map[string]interface{}); if !ok {
-        return entities, totalFound, errors.New("Failed to parse elastic result")
+    return result, nil
+}
+
+func (i *doc) Set(entities SetParams, indexName string, waitToRefresh ...bool) SetResult {
+    groups := []struct {
+        param string
+        entities []map[string]interface{}
+        getStmts func([]map[string]interface{}, string) ([]string, error)
+    }{
+        {"ToAdd", entities.ToAdd, getAddStmts},
+        {"ToUpdate", entities.ToUpdate, getUpdateStmts},
+        {"ToDelete", entities.ToDelete, getDeleteStmts},
+        {"ToIndex", entities.ToIndex, getIndexStmts},
+        {"ToUpsert", entities.ToUpsert, getUpsertStmts},
     }
 
-    entities = hits["hits"].([]interface{})
-    totalFound = hits["total"].(map[string]interface{})["value"].(int)
+    var stmts []string
+    var origins []setOrigin
+    for _, group := range groups {
+        groupStmts, err := group.getStmts(group.entities, indexName)
+        if err != nil {
+            return SetResult{Errors: []error{err}}
+        }
 
-    return entities, totalFound, nil
+        stmts = append(stmts, groupStmts...)
+        for pos := range groupStmts {
+            origins = append(origins, setOrigin{group.param, pos})
+        }
+    }
+
+    return i.bulk(stmts, origins, waitToRefresh...)
 }
 
-func (i *doc) Set(entities SetParams, indexName string, waitToRefresh ...bool) SetResult {
-	addStmts, err := getAddStmts(entities.ToAdd, indexName)
+// bulk sends the stmts in a single _bulk request, origins map the stmts
+// to their SetParams entities and may be nil
+func (i *doc) bulk(stmts []string, origins []setOrigin, waitToRefresh ...bool) SetResult {
+	endpoint := "/_bulk"
+    result, err := i.client.RequestCtx(i.ctx, MethodPost, endpoint, strings.Join(stmts, ""), waitToRefresh...)
 	if err != nil {
-		return SetResult{0, 0, 0, 0, []error{err}}
+        return SetResult{Errors: []error{err}}
 	}
 
-	updateStmts, err := getUpdateStmts(entities.ToUpdate, indexName)
-	if err != nil {
-		return SetResult{0, 0, 0, 0, []error{err}}
-	}
+    // items rejected with 429 are resent on their own when writes are retried
+    result, err = i.client.resendRejected(i.ctx, result, stmts, waitToRefresh...)
 
-    deleteStmts, err := getDeleteStmts(entities.ToDelete, indexName)
-	if err != nil {
-        return SetResult{0, 0, 0, 0, []error{err}}
-	}
-    
-	endpoint := "/_bulk"
-    result, err := Request(MethodPost, endpoint, addStmts + updateStmts + deleteStmts, waitToRefresh...)
-	if err != nil {
-        return SetResult{0, 0, 0, 0, []error{err}}
-	}
+	res := parseSetResponse(result, origins)
+    if err != nil {
+        res.Errors = append(res.Errors, err)
+    }
+
+    return res
+}
+
+func (o GetOptions) query() string {
+    values := url.Values{}
+    if o.Routing != "" {
+        values.Set("routing", o.Routing)
+    }
+    if o.Preference != "" {
+        values.Set("preference", o.Preference)
+    }
+    if o.Realtime != nil {
+        values.Set("realtime", strconv.FormatBool(*o.Realtime))
+    }
+    if o.Refresh {
+        values.Set("refresh", "true")
+    }
+    if len(o.SourceIncludes) > 0 {
+        values.Set("_source_includes", strings.Join(o.SourceIncludes, ","))
+    }
+    if len(o.SourceExcludes) > 0 {
+        values.Set("_source_excludes", strings.Join(o.SourceExcludes, ","))
+    }
+    if len(o.StoredFields) > 0 {
+        values.Set("stored_fields", strings.Join(o.StoredFields, ","))
+    }
+    if o.Version > 0 {
+        values.Set("version", strconv.FormatInt(o.Version, 10))
+    }
+    if o.VersionType != "" {
+        values.Set("version_type", o.VersionType)
+    }
+
+    if len(values) == 0 {
+        return ""
+    }
+
+    return "?" + values.Encode()
+}
+
+func (o MGetOptions) query() string {
+    values := url.Values{}
+    if len(o.SourceIncludes) > 0 {
+        values.Set("_source_includes", strings.Join(o.SourceIncludes, ","))
+    }
+    if len(o.SourceExcludes) > 0 {
+        values.Set("_source_excludes", strings.Join(o.SourceExcludes, ","))
+    }
+    if o.Routing != "" {
+        values.Set("routing", o.Routing)
+    }
+
+    if len(values) == 0 {
+        return ""
+    }
+
+    return "?" + values.Encode()
+}
+
+// query encodes the options onto the meta values of the entity, the options take precedence
+func (o WriteOptions) query(values url.Values) string {
+    if o.IfPrimaryTerm > 0 {
+        values.Set("if_seq_no", strconv.FormatInt(o.IfSeqNo, 10))
+        values.Set("if_primary_term", strconv.FormatInt(o.IfPrimaryTerm, 10))
+    }
+    if o.VersionType != "" {
+        values.Set("version", strconv.FormatInt(o.Version, 10))
+        values.Set("version_type", o.VersionType)
+    }
+    if o.Routing != "" {
+        values.Set("routing", o.Routing)
+    }
+
+    return encodeQuery(values)
+}
+
+// query encodes the options along with the bulk meta( routing, if_seq_no, ... ) of the entity,
+// the options take precedence over the meta
+func (o PatchOptions) query(meta map[string]interface{}) string {
+    values := metaValues(meta)
+
+    if o.IfPrimaryTerm > 0 {
+        values.Set("if_seq_no", strconv.FormatInt(o.IfSeqNo, 10))
+        values.Set("if_primary_term", strconv.FormatInt(o.IfPrimaryTerm, 10))
+    }
+    if o.RetryOnConflict > 0 {
+        values.Set("retry_on_conflict", strconv.Itoa(o.RetryOnConflict))
+    }
+    if o.Routing != "" {
+        values.Set("routing", o.Routing)
+    }
+
+    if o.ReturnSource && len(o.SourceIncludes) == 0 && len(o.SourceExcludes) == 0 {
+        values.Set("_source", "true")
+    }
+    if len(o.SourceIncludes) > 0 {
+        values.Set("_source_includes", strings.Join(o.SourceIncludes, ","))
+    }
+    if len(o.SourceExcludes) > 0 {
+        values.Set("_source_excludes", strings.Join(o.SourceExcludes, ","))
+    }
+
+    if len(values) == 0 {
+        return ""
+    }
 
-	return parseSetResponse(result)
+    return "?" + values.Encode()
 }
@@ -1,9 +1,12 @@
 package elastic
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"strconv"
 	"strings"
@@ -11,41 +14,11 @@ import (
 )
 
 func Init(config Config) error {
-    if config.Host == "" {
-        return errors.New("Elastic host is not set")
-    }
-    elasticConfig = config
-
-    patternStr := "%s"
-    var patternsArgs []interface{}
-
-    if elasticConfig.User != "" {
-        patternsArgs = append(patternsArgs, elasticConfig.User)
-
-        if elasticConfig.Password != "" {
-            patternStr = "%s:%s@" + patternStr
-            patternsArgs = append(patternsArgs, elasticConfig.Password)
-        } else {
-            patternStr = "%s@" + patternStr
-        }
-    }
-
-    patternsArgs = append(patternsArgs, elasticConfig.Host)
-
-    if elasticConfig.Port != 0 {
-        patternStr = patternStr + ":%d"
-        patternsArgs = append(patternsArgs, elasticConfig.Port)
-    }
-
-    patternStr = "https://" + patternStr
-
-    elasticUrl = fmt.Sprintf(patternStr, patternsArgs...)
-
-    return nil
+    return defaultClient.init(config)
 }
 
 func IsInitiated() bool {
-    return elasticUrl != ""
+    return defaultClient.IsInitiated()
 }
 
 func toJson(entity interface{}) (string, error) {
@@ -57,53 +30,151 @@ func toJson(entity interface{}) (string, error) {
 	return string(res), nil
 }
 
-func request(method Method, endpoint string, params string, waitToRefresh ...bool) (interface{}, error) {
-	if !IsInitiated() {
-        return nil, errors.New("elastic lib is not initiated")
+// request sends the query with retries and returns the decoded body with the http status.
+// Non 2xx responses are returned as *ElasticError along with their body
+func (c *Client) request(ctx context.Context, method Method, endpoint string, params string, waitToRefresh ...bool) (interface{}, int, error) {
+    if !c.IsInitiated() {
+        return nil, 0, ErrNotInitiated
     }
 
+    path := requestPath(endpoint, waitToRefresh...)
+
+    policy := c.getRetryPolicy()
+    attempts := policy.attemptsFor(method)
+    for attempt := 1; ; attempt++ {
+        result, status, err := c.perform(ctx, method, path, params)
+
+        var connErr *ConnectionError
+        retryable := errors.As(err, &connErr) || policy.isRetryableStatus(status)
+        if attempt >= attempts || !retryable {
+            return result, status, err
+        }
+
+        err = sleepCtx(ctx, method, endpoint, policy.backoff(attempt))
+        if err != nil {
+            return nil, 0, err
+        }
+    }
+}
+
+// requestPath prefixes the endpoint with "/" and appends refresh=wait_for when asked
+func requestPath(endpoint string, waitToRefresh ...bool) string {
     if !strings.HasPrefix(endpoint, "/") {
         endpoint = "/" + endpoint
     }
 
-    url := elasticUrl + endpoint
     if len(waitToRefresh) > 0 && waitToRefresh[0] {
-		url += "?refresh=wait_for"
-	}
+        if strings.Contains(endpoint, "?") {
+            return endpoint + "&refresh=wait_for"
+        }
+
+        return endpoint + "?refresh=wait_for"
+    }
+
+    return endpoint
+}
 
-    if len(params) > 0 {
-	    lastQuery = url + "\n" + params
+// perform sends a single request to the next node, returning the decoded body
+// and the http status( 0 when no response was received ).
+// Empty bodies( HEAD, 204 ) are returned as nil
+func (c *Client) perform(ctx context.Context, method Method, path string, params string) (interface{}, int, error) {
+    n := c.nextNode()
+	if n == nil {
+        return nil, 0, ErrNotInitiated
     }
 
-    req, err := http.NewRequest(method.String(), url, strings.NewReader(params))
+    url := n.url + path
+
+    req, err := http.NewRequestWithContext(ctx, method.String(), url, strings.NewReader(params))
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
     req.Header.Add("Content-Type", "application/json")
 
-    resp, err := http.DefaultClient.Do(req)
+    auth, err := c.authorization(ctx)
+    if err != nil {
+        return nil, 0, err
+    }
+    if auth != "" {
+        req.Header.Set("Authorization", auth)
+    }
+
+    resp, err := c.getHttpClient().Do(req)
 	if err != nil {
-		return nil, err
+        if ctxErr := ctx.Err(); ctxErr != nil {
+            return nil, 0, &ContextError{method, path, ctxErr}
+        }
+        c.markDead(n)
+
+		return nil, 0, &ConnectionError{method, path, err}
 	}
+    defer resp.Body.Close()
+    c.markAlive(n)
+
+    body, err := ioutil.ReadAll(resp.Body)
+    if err != nil {
+        return nil, resp.StatusCode, err
+    }
 
 	var result interface{}
-	d := json.NewDecoder(resp.Body)
-	// use json.Number instead of float64
-	d.UseNumber()
-	if err := d.Decode(&result); err != nil {
-		return nil, err
-	}
+    if len(bytes.TrimSpace(body)) > 0 {
+        d := json.NewDecoder(bytes.NewReader(body))
+        // use json.Number instead of float64
+        d.UseNumber()
+        if err := d.Decode(&result); err != nil {
+            if !isSuccessStatus(resp.StatusCode) {
+                return nil, resp.StatusCode, &ElasticError{StatusCode: resp.StatusCode, Reason: string(body)}
+            }
 
-	return result, nil
+            return nil, resp.StatusCode, err
+        }
+    }
+
+    if !isSuccessStatus(resp.StatusCode) {
+        return result, resp.StatusCode, statusError(resp.StatusCode, result)
+    }
+
+	return result, resp.StatusCode, nil
 }
 
-func Request(method Method, endpoint string, params string, waitToRefresh ...bool) (map[string]interface{}, error) {
-    result, err := request(method, endpoint, params, waitToRefresh...)
-    if err != nil {
+func isSuccessStatus(status int) bool {
+    return status >= 200 && status < 300
+}
+
+// statusError builds the error of a non 2xx response,
+// falling back to the status text when the body has no "error"
+func statusError(status int, result interface{}) *ElasticError {
+    if body, ok := result.(map[string]interface{}); ok {
+        if elErr, ok := body["error"]; ok {
+            return newElasticError(status, elErr)
+        }
+    }
+
+    return &ElasticError{StatusCode: status, Reason: http.StatusText(status)}
+}
+
+// Request sends a query to the cluster and returns the decoded json object
+func (c *Client) Request(method Method, endpoint string, params string, waitToRefresh ...bool) (map[string]interface{}, error) {
+    return c.RequestCtx(context.Background(), method, endpoint, params, waitToRefresh...)
+}
+
+// RequestCtx is Request bound to ctx.
+// Non 2xx responses return *ElasticError together with the decoded body
+func (c *Client) RequestCtx(ctx context.Context, method Method, endpoint string, params string, waitToRefresh ...bool) (map[string]interface{}, error) {
+    result, _, err := c.request(ctx, method, endpoint, params, waitToRefresh...)
+    if result == nil {
         return nil, err
     }
 
-    return result.(map[string]interface{}), nil
+    resultMap, ok := result.(map[string]interface{}); if !ok && err == nil {
+        return nil, errors.New(fmt.Sprintf("Unexpected elastic response: %v", result))
+    }
+
+    return resultMap, err
+}
+
+func Request(method Method, endpoint string, params string, waitToRefresh ...bool) (map[string]interface{}, error) {
+    return defaultClient.Request(method, endpoint, params, waitToRefresh...)
 }
 
 func FromElasticDate(date string) (string, error) {
@@ -128,177 +199,466 @@ func ToElasticDate(date string) (string, error) {
 	return dateParsed.Format(DateFormatElastic), nil
 }
 
-func getAddStmts(entities []map[string]interface{}, indexName string) (string, error) {
+// bulkMetaKeys maps the per-item meta keys of Set entities to their bulk action fields
+var bulkMetaKeys = map[string]string{
+    "_routing": "routing",
+    "_if_seq_no": "if_seq_no",
+    "_if_primary_term": "if_primary_term",
+    "_retry_on_conflict": "retry_on_conflict",
+    "_pipeline": "pipeline",
+}
+
+// splitBulkEntity returns the action meta and the document fields of entity, leaving entity as it is.
+// _id is put into the meta when useId is set and required when requireId is
+func splitBulkEntity(action Action, entity map[string]interface{}, indexName string, useId bool, requireId bool) (map[string]interface{}, map[string]interface{}, error) {
+    meta := map[string]interface{}{"_index": indexName}
+    source := make(map[string]interface{}, len(entity))
+    for k, v := range entity {
+        if field, ok := bulkMetaKeys[k]; ok {
+            meta[field] = v
+            continue
+        }
+
+        switch k {
+        case "_id", "_script", "_upsert":
+            continue
+        }
+
+        source[k] = v
+    }
+
+    id, hasId := entity["_id"]
+    if requireId && !hasId {
+        return nil, nil, errors.New(fmt.Sprintf("No _id transmitted for %s stmts: %v", action, entity))
+    }
+    if useId && hasId {
+        idStr, ok := id.(string); if !ok || idStr == "" {
+            return nil, nil, errors.New(fmt.Sprintf("Invalid _id transmitted for %s stmts: %v", action, entity))
+        }
+        meta["_id"] = idStr
+    }
+
+    if _, ok := meta["retry_on_conflict"]; ok && action != ActionUpdate {
+        return nil, nil, errors.New(fmt.Sprintf("_retry_on_conflict is only supported by updates: %v", entity))
+    }
+    if _, ok := meta["pipeline"]; ok && (action == ActionUpdate || action == ActionDelete) {
+        return nil, nil, errors.New(fmt.Sprintf("_pipeline is not supported by %s stmts: %v", action, entity))
+    }
+
+    _, hasScript := entity["_script"]
+    _, hasUpsert := entity["_upsert"]
+    if (hasScript || hasUpsert) && action != ActionUpdate {
+        return nil, nil, errors.New(fmt.Sprintf("_script and _upsert are only supported by updates: %v", entity))
+    }
+
+    return meta, source, nil
+}
+
+// bulkStmt joins the action line and the optional document line
+func bulkStmt(action Action, meta map[string]interface{}, body interface{}) (string, error) {
+    metaJson, err := toJson(map[string]interface{}{action.String(): meta})
+    if err != nil {
+        return "", errors.New(fmt.Sprintf("Failed to json elastic entity: %v", err))
+    }
+
+    if body == nil {
+        return metaJson + "\n", nil
+    }
+
+    bodyJson, err := toJson(body)
+    if err != nil {
+        return "", errors.New(fmt.Sprintf("Failed to json elastic entity: %v", err))
+    }
+
+    return metaJson + "\n" + bodyJson + "\n", nil
+}
+
+// getAddStmts builds create stmts, the _id is generated by elastic when missing
+func getAddStmts(entities []map[string]interface{}, indexName string) ([]string, error) {
 	if len(entities) < 1 {
-		return "", nil
+		return nil, nil
+	}
+
+	var stmts []string
+	for _, entity := range entities {
+        meta, source, err := splitBulkEntity(ActionCreate, entity, indexName, true, false)
+        if err != nil {
+            return nil, err
+        }
+
+        stmt, err := bulkStmt(ActionCreate, meta, source)
+        if err != nil {
+            return nil, err
+        }
+
+		stmts = append(stmts, stmt)
 	}
 
-	createStmt := "{\"create\":{\"_index\":\"" + indexName + "\"}}"
+	return stmts, nil
+}
 
-	stmts := ""
+// getIndexStmts builds create-or-replace stmts, the _id is generated by elastic when missing
+func getIndexStmts(entities []map[string]interface{}, indexName string) ([]string, error) {
+	if len(entities) < 1 {
+		return nil, nil
+	}
+
+	var stmts []string
 	for _, entity := range entities {
-		delete(entity, "_id")
+        meta, source, err := splitBulkEntity(ActionIndex, entity, indexName, true, false)
+        if err != nil {
+            return nil, err
+        }
 
-		entJson, err := toJson(entity)
-		if err != nil {
-			return "", errors.New(fmt.Sprintf("Failed to json elastic entity: %v", err))
-		}
+        stmt, err := bulkStmt(ActionIndex, meta, source)
+        if err != nil {
+            return nil, err
+        }
 
-		stmts += createStmt + "\n" + entJson + "\n"
+		stmts = append(stmts, stmt)
 	}
 
 	return stmts, nil
 }
 
-func getUpdateStmts(entities []map[string]interface{}, indexName string) (string, error) {
+// getUpdateStmts builds partial updates, or scripted ones when the entity has a _script.
+// _upsert is the document created when the entity doesn't exist
+func getUpdateStmts(entities []map[string]interface{}, indexName string) ([]string, error) {
+    return getUpdateStmtsAs(entities, indexName, false)
+}
+
+// getUpsertStmts builds partial updates which create the document when it doesn't exist
+func getUpsertStmts(entities []map[string]interface{}, indexName string) ([]string, error) {
+    return getUpdateStmtsAs(entities, indexName, true)
+}
+
+func getUpdateStmtsAs(entities []map[string]interface{}, indexName string, docAsUpsert bool) ([]string, error) {
 	if len(entities) < 1 {
-		return "", nil
+		return nil, nil
 	}
 
-	stmts := ""
+	var stmts []string
 	for _, entity := range entities {
-        id, ok := entity["_id"].(string); if !ok {
-            return "", errors.New(fmt.Sprintf("No _id transmitted for update stmts: %v", entity))
+        meta, source, err := splitBulkEntity(ActionUpdate, entity, indexName, true, true)
+        if err != nil {
+            return nil, err
         }
 
-		updateStmt := "{\"update\":{\"_index\":\"" + indexName + "\",\"_id\": \"" + id + "\"}}"
+        body, err := updateBody(entity, source, docAsUpsert)
+        if err != nil {
+            return nil, err
+        }
 
-		delete(entity, "_id")
-		entJson, err := toJson(entity)
-		if err != nil {
-			return "", errors.New(fmt.Sprintf("Failed to json elastic entity: %v", err))
-		}
+        stmt, err := bulkStmt(ActionUpdate, meta, body)
+        if err != nil {
+            return nil, err
+        }
 
-		stmts += updateStmt + "\n{\"doc\":" + entJson + "}\n"
+		stmts = append(stmts, stmt)
 	}
 
 	return stmts, nil
 }
 
-func getDeleteStmts(entities []map[string]interface{}, indexName string) (string, error) {
+// updateBody builds the body of an update from the document fields and the
+// _script/_upsert keys of entity
+func updateBody(entity map[string]interface{}, source map[string]interface{}, docAsUpsert bool) (map[string]interface{}, error) {
+    body := map[string]interface{}{}
+
+    if value, ok := entity["_script"]; ok {
+        if docAsUpsert {
+            return nil, errors.New(fmt.Sprintf("Scripted updates can't be upserted as doc, use _upsert: %v", entity))
+        }
+        if len(source) > 0 {
+            return nil, errors.New(fmt.Sprintf("Scripted updates can't have document fields: %v", entity))
+        }
+
+        script, err := scriptOf(value)
+        if err != nil {
+            return nil, err
+        }
+        body["script"] = script
+    } else {
+        body["doc"] = source
+        if docAsUpsert {
+            body["doc_as_upsert"] = true
+        }
+    }
+
+    if upsert, ok := entity["_upsert"]; ok {
+        body["upsert"] = upsert
+    }
+
+    return body, nil
+}
+
+func getDeleteStmts(entities []map[string]interface{}, indexName string) ([]string, error) {
 	if len(entities) < 1 {
-		return "", nil
+		return nil, nil
 	}
 
-	stmts := ""
+	var stmts []string
 	for _, entity := range entities {
-        id, ok := entity["_id"].(string); if !ok {
-            return "", errors.New(fmt.Sprintf("No _id transmitted for delete stmts: %v", entity))
+        meta, _, err := splitBulkEntity(ActionDelete, entity, indexName, true, true)
+        if err != nil {
+            return nil, err
         }
 
-		stmts += "{\"delete\":{\"_index\":\"" + indexName + "\",\"_id\": \"" + id + "\"}}\n"
+        stmt, err := bulkStmt(ActionDelete, meta, nil)
+        if err != nil {
+            return nil, err
+        }
+
+		stmts = append(stmts, stmt)
 	}
 
 	return stmts, nil
 }
 
-func parseSetItemResp(resp interface{}, action Action) (bool, bool, error) {
-	resp, success := resp.(map[string]interface{})[action.String()]
-	if !success {
-		return false, false, nil
+func getMSearchStmts(requests []SearchRequest) ([]string, error) {
+	if len(requests) < 1 {
+		return nil, nil
 	}
 
-	result, success := resp.(map[string]interface{})["result"]
-	if success {
-		if result.(string) == action.String()+"d" {
-			return true, true, nil
+	var stmts []string
+	for _, request := range requests {
+        if request.Index == "" {
+            return nil, errors.New(fmt.Sprintf("No index transmitted for msearch stmt: %v", request.Query))
+        }
+
+        header := map[string]interface{}{"index": request.Index}
+        if request.Routing != "" {
+            header["routing"] = request.Routing
+        }
+        if request.Preference != "" {
+            header["preference"] = request.Preference
+        }
+
+		headerJson, err := toJson(header)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Failed to json elastic msearch header: %v", err))
 		}
 
-		return true, false, nil
-	}
+        query := request.Query
+        if query == nil {
+            query = map[string]interface{}{}
+        }
 
-	resp, success = resp.(map[string]interface{})["error"]
-	if !success {
-		return true, false, nil
-	}
+		queryJson, err := toJson(query)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Failed to json elastic query: %v", err))
+		}
 
-	resp, success = resp.(map[string]interface{})["reason"]
-	if !success {
-		return true, false, nil
+		stmts = append(stmts, headerJson + "\n" + queryJson + "\n")
 	}
 
-	return true, false, errors.New(resp.(string))
+	return stmts, nil
 }
 
-func parseSetResponse(result map[string]interface{}) SetResult {
-	res := SetResult{}
+// parseMSearchResponse keeps the order of the requests, a failed search only sets its own Err
+func parseMSearchResponse(result map[string]interface{}, count int) ([]MSearchResult, error) {
+    responses, ok := result["responses"].([]interface{}); if !ok || len(responses) != count {
+        return nil, errors.New(fmt.Sprintf("Unknown error at MSearch: %v", result))
+    }
+
+    res := make([]MSearchResult, 0, count)
+    for _, item := range responses {
+        response, ok := item.(map[string]interface{}); if !ok {
+            res = append(res, MSearchResult{Err: errors.New(fmt.Sprintf("Unknown msearch response: %v", item))})
+            continue
+        }
+
+        if elErr := parseError(response); elErr != nil {
+            res = append(res, MSearchResult{Err: elErr})
+            continue
+        }
+
+        searchResult, err := parseSearchResult(response)
+        res = append(res, MSearchResult{Result: searchResult, Err: err})
+    }
+
+    return res, nil
+}
 
-	items := result["items"].([]interface{})
-	for _, item := range items {
-		actions := []Action{
-			ActionCreate,
-		    ActionUpdate,
-			ActionDelete,
+// parseSetItem parses a single item of a _bulk response,
+// false when the item has no known action
+func parseSetItem(item interface{}) (SetItemResult, bool) {
+	res := SetItemResult{Position: -1}
+
+	itemMap, _ := item.(map[string]interface{})
+	for _, action := range []Action{ActionCreate, ActionUpdate, ActionDelete, ActionIndex} {
+		resp, ok := itemMap[action.String()].(map[string]interface{}); if !ok {
+			continue
+		}
+
+		res.Action = action
+		res.Index, _ = resp["_index"].(string)
+		res.ID, _ = resp["_id"].(string)
+		res.Status = toInt(resp["status"])
+		res.Result, _ = resp["result"].(string)
+		res.Version = int64(toInt(resp["_version"]))
+		res.SeqNo = int64(toInt(resp["_seq_no"]))
+		res.PrimaryTerm = int64(toInt(resp["_primary_term"]))
+
+		if elErr, ok := resp["error"]; ok {
+			err := newElasticError(res.Status, elErr)
+			err.create = action == ActionCreate
+			res.ErrorType = err.Type
+			res.ErrorReason = err.Reason
+			res.Err = err
+
+			return res, true
 		}
 
-		foundAction := false
-		for _, action := range actions {
-			isAction, success, err := parseSetItemResp(item, action)
-			if isAction {
-				foundAction = true
-
-				if success {
-					switch action {
-					case "create":
-						res.Added++
-						break
-					case "update":
-						res.Updated++
-						break
-					case "delete":
-					default:
-						res.Deleted++
-						break
-					}
-				} else {
-					res.Failed++
-				}
-
-				if err != nil {
-					res.Errors = append(res.Errors, err)
-				}
-
-				continue
+		if !isSuccessStatus(res.Status) || !res.succeeded() {
+			res.ErrorType = res.Result
+			res.ErrorReason = fmt.Sprintf("entity [%s] %s", res.ID, res.Result)
+			if res.Result == "not_found" {
+				res.ErrorReason = fmt.Sprintf("entity [%s] not found", res.ID)
 			}
+			res.Err = &ElasticError{StatusCode: res.Status, Type: res.ErrorType, Reason: res.ErrorReason}
 		}
 
-		if !foundAction {
+		return res, true
+	}
+
+	return res, false
+}
+
+func (r SetItemResult) succeeded() bool {
+	switch r.Result {
+	case "created", "deleted":
+		return true
+	case "updated", "noop":
+		// noop is an update which didn't change the document
+		return r.Action != ActionCreate && r.Action != ActionDelete
+	}
+
+	return false
+}
+
+// setOrigin is the SetParams slice and position of a stmt
+type setOrigin struct {
+	param string
+	position int
+}
+
+// parseSetResponse counts the items and keeps them in the order of the stmts.
+// Without origins Position is the index of the stmt
+func parseSetResponse(result map[string]interface{}, origins []setOrigin) SetResult {
+	res := SetResult{}
+
+	items, ok := result["items"].([]interface{}); if !ok {
+		res.Errors = append(res.Errors, errors.New(fmt.Sprintf("Unknown error at Set: %v", result)))
+		return res
+	}
+
+	if len(origins) != len(items) {
+		origins = nil
+	}
+
+	for k, item := range items {
+		itemRes, ok := parseSetItem(item)
+		if !ok {
 			res.Failed++
 
 			jsonItem, err := toJson(item)
 			if err != nil {
-				res.Errors = append(res.Errors, errors.New("no action: " + err.Error()))
+				itemRes.Err = errors.New("no action: " + err.Error())
 			} else {
-				res.Errors = append(res.Errors, errors.New("no action: " + jsonItem))
+				itemRes.Err = errors.New("no action: " + jsonItem)
 			}
+			res.Errors = append(res.Errors, itemRes.Err)
+			res.Items = append(res.Items, itemRes)
+
+			continue
 		}
-    }
+
+		if origins != nil {
+			itemRes.param = origins[k].param
+			itemRes.Position = origins[k].position
+		} else {
+			itemRes.Position = k
+		}
+		res.Items = append(res.Items, itemRes)
+
+		if itemRes.Err != nil {
+			res.Failed++
+			res.Errors = append(res.Errors, itemRes.Err)
+
+			continue
+		}
+
+		switch itemRes.Result {
+		case "created":
+			res.Added++
+		case "deleted":
+			res.Deleted++
+		default:
+			res.Updated++
+		}
+	}
 
 	return res
 }
 
 func parseEditItemResponse(result map[string]interface{}, action string) (string, error) {
+    res, err := parseWriteResponse(result, action)
+    return res.ID, err
+}
+
+// parseWriteResponse accepts the results given, action + "d" by default
+func parseWriteResponse(result map[string]interface{}, action string, accepted ...string) (WriteResult, error) {
+    res := WriteResult{
+        Version: int64(toInt(result["_version"])),
+        SeqNo: int64(toInt(result["_seq_no"])),
+        PrimaryTerm: int64(toInt(result["_primary_term"])),
+    }
+    res.Index, _ = result["_index"].(string)
+
     entId, entIdOk := result["_id"].(string)
     status, statusOk := result["result"].(string)
+    res.ID = entId
+    res.Result = status
+
+    if len(accepted) == 0 {
+        accepted = []string{action + "d"}
+    }
+
+    isAccepted := false
+    for _, a := range accepted {
+        isAccepted = isAccepted || status == a
+    }
 
-    if !entIdOk || !statusOk || status != action + "d" {
-        if !statusOk {
-            status, statusOk = result["error"].(string); if !statusOk {
+    if !entIdOk || !statusOk || !isAccepted {
+        err := parseError(result)
+        if err == nil && status == "not_found" {
+            err = &ElasticError{Type: status, Reason: fmt.Sprintf("entity [%s] not found", entId)}
+        }
+        if err == nil {
+            if !statusOk {
                 status = "unknown"
             }
+            err = errors.New(status)
         }
 
-        return entId, errors.New(fmt.Sprintf("Failed to %s elastic entity: %v", action, status))
+        return res, fmt.Errorf("Failed to %s elastic entity: %w", action, err)
     }
 
-    return entId, nil
+    return res, nil
 }
 
-func CatIndices(target ...string) ([]Indice, error) {
+// CatIndices lists the cluster indices, optionally narrowed down to target
+func (c *Client) CatIndices(target ...string) ([]Indice, error) {
+    return c.CatIndicesCtx(context.Background(), target...)
+}
+
+// CatIndicesCtx is CatIndices bound to ctx
+func (c *Client) CatIndicesCtx(ctx context.Context, target ...string) ([]Indice, error) {
     var indices []Indice
 
-    if !IsInitiated() {
-        return indices, errors.New("Elastic is not initiated")
+    if !c.IsInitiated() {
+        return indices, ErrNotInitiated
     }
 
     endpoint := "/_cat/indices"
@@ -307,12 +667,16 @@ func CatIndices(target ...string) ([]Indice, error) {
     }
     endpoint += "?format=json"
 
-    result, err := request(MethodGet, endpoint, "")
+    result, _, err := c.request(ctx, MethodGet, endpoint, "")
     if err != nil {
         return indices, err
     }
 
-    for _, i := range result.([]interface{}) {
+    items, ok := result.([]interface{}); if !ok {
+        return indices, errors.New(fmt.Sprintf("Unknown error at CatIndices: %v", result))
+    }
+
+    for _, i := range items {
         item := i.(map[string]interface{})
         index, ok := item["index"]; if !ok {
             return indices, errors.New(fmt.Sprintf("No index found in cat indices response: %v", item))
@@ -353,25 +717,17 @@ func parseError(result map[string]interface{}) error {
         return nil
     }
 
-    return errors.New(fmt.Sprintf(
-        "[Elastic error] %v: %v", 
-        elErr.(map[string]interface{})["type"],
-        elErr.(map[string]interface{})["reason"],
-    ))
+    return newElasticError(toInt(result["status"]), elErr)
 }
 
-func Docs() *Doc {
-    if docs == nil {
-        docs = &doc{}
-    }
-
-    return docs
+func CatIndices(target ...string) ([]Indice, error) {
+    return defaultClient.CatIndices(target...)
 }
 
-func Indexes() *Index {
-    if indexes == nil {
-        indexes = &index{}
-    }
+func Docs() Doc {
+    return defaultClient.Docs()
+}
 
-    return indexes
+func Indexes() Index {
+    return defaultClient.Indexes()
 }
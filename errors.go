@@ -0,0 +1,142 @@
+package elastic
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "strconv"
+)
+
+var (
+    ErrNotInitiated = errors.New("elastic lib is not initiated")
+    ErrNotFound = errors.New("elastic: not found")
+    ErrIndexNotFound = errors.New("elastic: index not found")
+    ErrVersionConflict = errors.New("elastic: version conflict")
+    ErrResourceAlreadyExists = errors.New("elastic: resource already exists")
+    // ErrDocumentExists is a version conflict of a create, its _id already exists
+    ErrDocumentExists = errors.New("elastic: document already exists")
+)
+
+// ConnectionError is returned when no response was received from a node.
+// Such requests are retried according to the RetryPolicy
+type ConnectionError struct {
+    Method Method
+    Endpoint string
+    Err error
+}
+
+func (e *ConnectionError) Error() string {
+    return fmt.Sprintf("Elastic request %s %s failed: %v", e.Method, e.Endpoint, e.Err)
+}
+
+func (e *ConnectionError) Unwrap() error {
+    return e.Err
+}
+
+// ElasticError is an error reported by the cluster.
+// It matches the Err* sentinels with errors.Is and unwraps to CausedBy
+type ElasticError struct {
+    StatusCode int
+    Type string
+    Reason string
+    Index string
+    RootCause []*ElasticError
+    CausedBy *ElasticError
+
+    // create is set for errors of create actions, whose version conflicts mean the document exists
+    create bool
+}
+
+func (e *ElasticError) Error() string {
+    if e.StatusCode != 0 {
+        return fmt.Sprintf("[Elastic error] %d %s: %s", e.StatusCode, e.Type, e.Reason)
+    }
+
+    return fmt.Sprintf("[Elastic error] %s: %s", e.Type, e.Reason)
+}
+
+func (e *ElasticError) Unwrap() error {
+    if e.CausedBy == nil {
+        return nil
+    }
+
+    return e.CausedBy
+}
+
+func (e *ElasticError) Is(target error) bool {
+    switch target {
+    case ErrNotFound:
+        return e.StatusCode == 404 || e.Type == "not_found" ||
+            e.Type == "index_not_found_exception" ||
+            e.Type == "document_missing_exception" ||
+            e.Type == "resource_not_found_exception"
+    case ErrIndexNotFound:
+        return e.Type == "index_not_found_exception"
+    case ErrVersionConflict:
+        return e.Type == "version_conflict_engine_exception"
+    case ErrResourceAlreadyExists:
+        return e.Type == "resource_already_exists_exception"
+    case ErrDocumentExists:
+        return e.create && e.Type == "version_conflict_engine_exception"
+    }
+
+    return false
+}
+
+func toInt(value interface{}) int {
+    switch v := value.(type) {
+    case json.Number:
+        n, _ := strconv.Atoi(v.String())
+        return n
+    case float64:
+        return int(v)
+    case int:
+        return v
+    }
+
+    return 0
+}
+
+// newElasticError builds the error from the "error" value of a response,
+// which is either a plain string or an object with type, reason and causes
+func newElasticError(status int, value interface{}) *ElasticError {
+    elErr := &ElasticError{StatusCode: status}
+
+    switch v := value.(type) {
+    case string:
+        elErr.Reason = v
+    case map[string]interface{}:
+        elErr.Type, _ = v["type"].(string)
+        elErr.Reason, _ = v["reason"].(string)
+        elErr.Index, _ = v["index"].(string)
+
+        rootCauses, _ := v["root_cause"].([]interface{})
+        for _, rootCause := range rootCauses {
+            elErr.RootCause = append(elErr.RootCause, newElasticError(0, rootCause))
+        }
+
+        if causedBy, ok := v["caused_by"]; ok {
+            elErr.CausedBy = newElasticError(0, causedBy)
+        }
+    default:
+        elErr.Reason = fmt.Sprintf("%v", v)
+    }
+
+    return elErr
+}
+
+// ContextError is returned when a request is aborted by its context.
+// It unwraps to context.Canceled or context.DeadlineExceeded
+type ContextError struct {
+    Method Method
+    Endpoint string
+    Err error
+}
+
+func (e *ContextError) Error() string {
+    return fmt.Sprintf("Elastic request %s %s aborted: %v", e.Method, e.Endpoint, e.Err)
+}
+
+func (e *ContextError) Unwrap() error {
+    return e.Err
+}
@@ -0,0 +1,237 @@
+package elastic
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "reflect"
+    "strings"
+)
+
+// Struct fields tagged with `elastic:"<key>"` receive the hit metadata,
+// e.g. `elastic:"_id"`, `elastic:"_version"`, `elastic:"_seq_no"` or `elastic:"_score"`.
+// Such fields are never sent as part of the document
+
+const metaTag = "elastic"
+
+// GetAs fetches the document and decodes its _source into T, nil when it doesn't exist
+func GetAs[T any](d Doc, entityId string, indexName string, opts ...GetOptions) (*T, error) {
+    document, err := d.GetDocument(entityId, indexName, opts...)
+    if err != nil || document == nil {
+        return nil, err
+    }
+
+    entity, err := decodeHit[T](document.raw)
+    if err != nil {
+        return nil, err
+    }
+
+    return &entity, nil
+}
+
+// MGetAs fetches the documents and decodes them into T. The result lines up
+// with entityIds, missing documents are nil
+func MGetAs[T any](d Doc, entityIds []string, indexName string) ([]*T, error) {
+    docs, err := d.MGet(entityIds, indexName)
+    if err != nil {
+        return nil, err
+    }
+
+    entities := make([]*T, len(docs))
+    for pos, item := range docs {
+        if item.Err != nil {
+            return nil, item.Err
+        }
+
+        if !item.Found {
+            continue
+        }
+
+        entity, err := decodeHit[T](item.raw)
+        if err != nil {
+            return nil, err
+        }
+
+        entities[pos] = &entity
+    }
+
+    return entities, nil
+}
+
+// SearchAs runs the query and decodes every hit into T, returning the total number of found documents
+func SearchAs[T any](d Doc, query map[string]interface{}, indexName string) ([]T, int, error) {
+    result, err := d.Search(query, indexName)
+    if err != nil {
+        return nil, 0, err
+    }
+
+    entities := make([]T, 0, len(result.Hits))
+    for _, hit := range result.Hits {
+        entity, err := decodeHit[T](hit.raw)
+        if err != nil {
+            return nil, 0, err
+        }
+
+        entities = append(entities, entity)
+    }
+
+    return entities, result.Total.Value, nil
+}
+
+// CreateFrom marshals entity through its json tags and creates it, under its _id field when set
+func CreateFrom[T any](d Doc, entity T, indexName string, waitToRefresh ...bool) (string, error) {
+    fields, err := toEntity(entity)
+    if err != nil {
+        return "", err
+    }
+
+    return d.Create(fields, indexName, waitToRefresh...)
+}
+
+func decodeHit[T any](hit map[string]interface{}) (T, error) {
+    var entity T
+
+    source, err := json.Marshal(hit["_source"])
+    if err != nil {
+        return entity, errors.New(fmt.Sprintf("Failed to json elastic source: %v", err))
+    }
+
+    err = json.Unmarshal(source, &entity)
+    if err != nil {
+        return entity, fmt.Errorf("Failed to decode elastic source into %T: %w", entity, err)
+    }
+
+    err = setMeta(reflect.ValueOf(&entity).Elem(), hit)
+    if err != nil {
+        return entity, err
+    }
+
+    return entity, nil
+}
+
+// setMeta copies hit metadata onto the fields tagged with `elastic:"<key>"`
+func setMeta(value reflect.Value, hit map[string]interface{}) error {
+    if value.Kind() != reflect.Struct {
+        return nil
+    }
+
+    valueType := value.Type()
+    for k := 0; k < valueType.NumField(); k++ {
+        field := valueType.Field(k)
+
+        key := field.Tag.Get(metaTag)
+        if key == "" || !field.IsExported() {
+            continue
+        }
+
+        meta, ok := hit[key]; if !ok || meta == nil {
+            continue
+        }
+
+        err := setField(value.Field(k), meta)
+        if err != nil {
+            return fmt.Errorf("Failed to set %s of %s: %w", key, valueType, err)
+        }
+    }
+
+    return nil
+}
+
+func setField(field reflect.Value, meta interface{}) error {
+    if field.Kind() == reflect.Ptr {
+        ptr := reflect.New(field.Type().Elem())
+        err := setField(ptr.Elem(), meta)
+        if err != nil {
+            return err
+        }
+
+        field.Set(ptr)
+        return nil
+    }
+
+    switch field.Kind() {
+    case reflect.String:
+        field.SetString(fmt.Sprintf("%v", meta))
+    case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+        number, ok := meta.(json.Number); if !ok {
+            return errors.New(fmt.Sprintf("not a number: %v", meta))
+        }
+
+        n, err := number.Int64()
+        if err != nil {
+            return err
+        }
+        field.SetInt(n)
+    case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+        number, ok := meta.(json.Number); if !ok {
+            return errors.New(fmt.Sprintf("not a number: %v", meta))
+        }
+
+        n, err := number.Int64()
+        if err != nil || n < 0 {
+            return errors.New(fmt.Sprintf("not an unsigned number: %v", meta))
+        }
+        field.SetUint(uint64(n))
+    case reflect.Float32, reflect.Float64:
+        number, ok := meta.(json.Number); if !ok {
+            return errors.New(fmt.Sprintf("not a number: %v", meta))
+        }
+
+        n, err := number.Float64()
+        if err != nil {
+            return err
+        }
+        field.SetFloat(n)
+    default:
+        return errors.New(fmt.Sprintf("unsupported field kind %s", field.Kind()))
+    }
+
+    return nil
+}
+
+// toEntity marshals entity through its json tags, leaving out the metadata fields.
+// A non-empty _id field is kept as the _id key
+func toEntity(entity interface{}) (map[string]interface{}, error) {
+    data, err := json.Marshal(entity)
+    if err != nil {
+        return nil, errors.New(fmt.Sprintf("Failed to json elastic entity: %v", err))
+    }
+
+    var fields map[string]interface{}
+    d := json.NewDecoder(bytes.NewReader(data))
+    d.UseNumber()
+    if err := d.Decode(&fields); err != nil {
+        return nil, errors.New(fmt.Sprintf("Elastic entity must be a json object: %T", entity))
+    }
+
+    value := reflect.Indirect(reflect.ValueOf(entity))
+    if value.Kind() != reflect.Struct {
+        return fields, nil
+    }
+
+    valueType := value.Type()
+    for k := 0; k < valueType.NumField(); k++ {
+        field := valueType.Field(k)
+        key := field.Tag.Get(metaTag)
+        if key == "" {
+            continue
+        }
+
+        delete(fields, jsonName(field))
+        if key == "_id" && field.Type.Kind() == reflect.String && value.Field(k).String() != "" {
+            fields["_id"] = value.Field(k).String()
+        }
+    }
+
+    return fields, nil
+}
+
+func jsonName(field reflect.StructField) string {
+    name := strings.Split(field.Tag.Get("json"), ",")[0]
+    if name == "" {
+        return field.Name
+    }
+
+    return name
+}
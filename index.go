@@ -1,54 +1,73 @@
 package elastic
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strconv"
 )
 
 type Index interface {
-    Get(indexName string, params map[string]interface{}) (map[string]interface{}, error)
+    Get(indexName string) (IndexStructure, error)
 
-    Exists(indexName string, params map[string]interface{}) ([]interface{}, error)
+    Exists(indexName string) (bool, error)
 
     Create(indexStruct IndexStructure, waitForActiveShards ...int) error
     
     Delete(indexName string) error
     
-    GetMapping(indexName string) (map[string]interface{}, error)
+    GetMapping(indexName string, fieldName string) (map[string]interface{}, error)
     
-    UpdateMapping(indexName string, props map[string]interface{}) error
+    UpdateMapping(indexName string, mappings map[string]interface{}) error
+
+    WithContext(ctx context.Context) Index
+}
+
+type index struct {
+    client *Client
+    ctx context.Context
 }
 
-type index struct {}
+// WithContext returns a copy of the indexes API whose requests are bound to ctx
+func (i *index) WithContext(ctx context.Context) Index {
+    return &index{i.client, ctx}
+}
 
 func (i *index) Get(indexName string) (IndexStructure, error) {
     var indexStructure IndexStructure
 
-    result, err := Request(MethodGet, "/" + indexName, "")
+    result, err := i.client.RequestCtx(i.ctx, MethodGet, "/" + indexName, "")
     if err != nil {
-        return indexStructure, err
+        return indexStructure, fmt.Errorf("Failed to get elastic index: %w", err)
+    }
+
+    elErr := parseError(result); if elErr != nil {
+        return indexStructure, elErr
     }
     
-    result, ok := result[ indexName ].(map[string]interface{}); if !ok {
+    indexResult, ok := result[ indexName ].(map[string]interface{}); if !ok {
         return indexStructure, errors.New(fmt.Sprintf("Unknown error at index.Get: %v", result))
     }
 
-    indexStructure.Name = result[ indexName ].(string)
-    indexStructure.Aliases = result["aliases"].(map[string]interface{})
-    indexStructure.Mappings = result["mappings"].(map[string]interface{})
-    indexStructure.Settings = result["settings"].(map[string]interface{})
+    indexStructure.Name = indexName
+    indexStructure.Aliases, _ = indexResult["aliases"].(map[string]interface{})
+    indexStructure.Mappings, _ = indexResult["mappings"].(map[string]interface{})
+    indexStructure.Settings, _ = indexResult["settings"].(map[string]interface{})
 
     return indexStructure, nil
 }
 
 func (i *index) Exists(indexName string) (bool, error) {
-    result, err := request(MethodHead, "/"+indexName, "")
+    _, status, err := i.client.request(i.ctx, MethodHead, "/"+indexName, "")
     if err != nil {
+        if status == 404 {
+            return false, nil
+        }
+
         return false, err 
     }
 
-    return result.(string) == "200 - true", nil
+    return status == 200, nil
 }
 
 func (i *index) Create(indexStruct IndexStructure, waitForActiveShards ...int) error {
@@ -62,9 +81,9 @@ func (i *index) Create(indexStruct IndexStructure, waitForActiveShards ...int) e
         endpoint += "?wait_for_active_shards="+strconv.Itoa(waitForActiveShards[0])
     }
 
-    result, err := Request(MethodPut, endpoint, entJson)
+    result, err := i.client.RequestCtx(i.ctx, MethodPut, endpoint, entJson)
     if err != nil {
-        return errors.New(fmt.Sprintf("Failed to create elastic index: %v", err))
+        return fmt.Errorf("Failed to create elastic index: %w", err)
     }
 
     indexName, ok := result["index"].(string)
@@ -81,13 +100,13 @@ func (i *index) Create(indexStruct IndexStructure, waitForActiveShards ...int) e
 }
 
 func (i *index) Delete(indexName string) error {
-    result, err := Request(MethodDelete, "/"+indexName, "")
+    result, err := i.client.RequestCtx(i.ctx, MethodDelete, "/"+indexName, "")
     if err != nil {
-        return errors.New(fmt.Sprintf("Failed to delete elastic index: %v", err))
+        return fmt.Errorf("Failed to delete elastic index: %w", err)
     }
 
     err = nil
-    if !result["acknowledged"].(bool) {
+    acknowledged, _ := result["acknowledged"].(bool); if !acknowledged {
         err = parseError(result)
         if err == nil {
             err = errors.New(fmt.Sprintf("Unknown error at index.Delete: %v", result))
@@ -103,19 +122,18 @@ func (i *index) GetMapping(indexName string, fieldName string) (map[string]inter
         endpoint += "/field/"+fieldName
     }
 
-    result, err := Request(MethodGet, endpoint, "")
+    result, err := i.client.RequestCtx(i.ctx, MethodGet, endpoint, "")
     if err != nil {
-        return nil, errors.New(fmt.Sprintf("Failed to get elastic mapping: %v", err))
+        return nil, fmt.Errorf("Failed to get elastic mapping: %w", err)
+    }
+
+    elErr := parseError(result); if elErr != nil {
+        return nil, elErr
     }
 
     result, ok := result[ indexName ].(map[string]interface{})
     if !ok {
-        err = parseError(result)
-        if err == nil {
-            err = errors.New(fmt.Sprintf("Unknown error at index.GetMapping: %v", result))
-        }
-
-        return nil, err
+        return nil, errors.New(fmt.Sprintf("Unknown error at index.GetMapping: %v", result))
     }
 
     result, ok = result["mappings"].(map[string]interface{}); if !ok {
@@ -141,14 +159,9 @@ func (i *index) UpdateMapping(indexName string, mappings map[string]interface{})
         return errors.New(fmt.Sprintf("Failed to json elastic mappings: %v", err))
     }
 
-    result, err := Request(MethodPut, "/"+indexName+"/_mapping", mappingsJson)
+    result, err := i.client.RequestCtx(i.ctx, MethodPut, "/"+indexName+"/_mapping", mappingsJson)
     if err != nil {
-        err = parseError(result)
-        if err == nil {
-            err = errors.New(fmt.Sprintf("Unknown error at index.UpdateMapping: %v", result))
-        }
-
-        return err
+        return fmt.Errorf("Failed to update elastic mapping: %w", err)
     }
 
     aknowledged, ok := result["acknowledged"].(bool); if !ok || !aknowledged {
@@ -0,0 +1,185 @@
+package elastic
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// node is a single cluster endpoint, all its fields are guarded by Client.mu
+type node struct {
+    url string
+    failures int
+    deadUntil time.Time
+}
+
+func (n *node) isAlive(now time.Time) bool {
+    return n.failures == 0 || !now.Before(n.deadUntil)
+}
+
+func nodeAddresses(config Config) []string {
+    var addresses []string
+
+    if config.Host != "" {
+        address := config.Host
+        if config.Port != 0 {
+            address += ":" + strconv.Itoa(config.Port)
+        }
+
+        addresses = append(addresses, address)
+    }
+
+    for _, address := range config.Nodes {
+        if address != "" {
+            addresses = append(addresses, address)
+        }
+    }
+
+    return addresses
+}
+
+// newNode builds the node url, credentials are never part of it
+// and are sent in the Authorization header instead
+func newNode(config Config, address string) *node {
+    scheme := config.Scheme
+    if scheme == "" {
+        scheme = DefaultScheme
+    }
+
+    return &node{url: scheme + "://" + address}
+}
+
+func newNodes(config Config, addresses []string) []*node {
+    nodes := make([]*node, 0, len(addresses))
+    for _, address := range addresses {
+        nodes = append(nodes, newNode(config, address))
+    }
+
+    return nodes
+}
+
+// nextNode picks nodes round-robin, skipping the dead ones.
+// When every node is dead the one which is due to be retried first is returned
+func (c *Client) nextNode() *node {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    if len(c.nodes) == 0 {
+        return nil
+    }
+
+    now := time.Now()
+    var fallback *node
+    for k := 0; k < len(c.nodes); k++ {
+        pos := (c.nodeIdx + k) % len(c.nodes)
+
+        n := c.nodes[pos]
+        if n.isAlive(now) {
+            c.nodeIdx = (pos + 1) % len(c.nodes)
+            return n
+        }
+
+        if fallback == nil || n.deadUntil.Before(fallback.deadUntil) {
+            fallback = n
+        }
+    }
+
+    return fallback
+}
+
+// markDead takes the node out of rotation, doubling the backoff
+// with every consecutive failure
+func (c *Client) markDead(n *node) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    backoff := c.config.DeadNodeBackoff
+    if backoff <= 0 {
+        backoff = DefaultDeadNodeBackoff
+    }
+
+    shift := n.failures
+    if shift > maxDeadNodeBackoffShift {
+        shift = maxDeadNodeBackoffShift
+    }
+
+    n.failures++
+    n.deadUntil = time.Now().Add(backoff << uint(shift))
+}
+
+func (c *Client) markAlive(n *node) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    n.failures = 0
+    n.deadUntil = time.Time{}
+}
+
+func (c *Client) nodeUrls() []string {
+    c.mu.RLock()
+    defer c.mu.RUnlock()
+
+    urls := make([]string, 0, len(c.nodes))
+    for _, n := range c.nodes {
+        urls = append(urls, n.url)
+    }
+
+    return urls
+}
+
+// DiscoverNodes replaces the node list with the http addresses
+// the cluster reports at /_nodes/http
+func (c *Client) DiscoverNodes() error {
+    return c.DiscoverNodesCtx(context.Background())
+}
+
+// DiscoverNodesCtx is DiscoverNodes bound to ctx
+func (c *Client) DiscoverNodesCtx(ctx context.Context) error {
+    result, err := c.RequestCtx(ctx, MethodGet, "/_nodes/http", "")
+    if err != nil {
+        return fmt.Errorf("Failed to discover elastic nodes: %w", err)
+    }
+
+    elErr := parseError(result); if elErr != nil {
+        return elErr
+    }
+
+    nodes, ok := result["nodes"].(map[string]interface{}); if !ok {
+        return errors.New(fmt.Sprintf("Unknown error at DiscoverNodes: %v", result))
+    }
+
+    var addresses []string
+    for _, n := range nodes {
+        httpInfo, ok := n.(map[string]interface{})["http"].(map[string]interface{}); if !ok {
+            continue
+        }
+
+        address, ok := httpInfo["publish_address"].(string); if !ok || address == "" {
+            continue
+        }
+
+        // publish_address may be reported as "hostname/ip:port"
+        if pos := strings.LastIndex(address, "/"); pos >= 0 {
+            address = address[pos+1:]
+        }
+
+        addresses = append(addresses, address)
+    }
+
+    if len(addresses) == 0 {
+        return errors.New(fmt.Sprintf("No http nodes found at DiscoverNodes: %v", result))
+    }
+    sort.Strings(addresses)
+
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    c.nodes = newNodes(c.config, addresses)
+    c.nodeIdx = 0
+
+    return nil
+}
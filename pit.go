@@ -0,0 +1,257 @@
+package elastic
+
+import (
+    "bytes"
+    "encoding/base64"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "net/url"
+)
+
+// Paginator walks the documents matched by a query with a point in time( PIT )
+// and search_after, which stays consistent and fast for deep pages.
+// It is not safe for concurrent use
+type Paginator struct {
+    doc *doc
+    query map[string]interface{}
+    indexName string
+    keepAlive string
+    pageSize int
+
+    pitId string
+    searchAfter []interface{}
+    cursorErr error
+    lastPage bool
+    done bool
+}
+
+// paginatorCursor is the state encoded into Paginator.Cursor tokens
+type paginatorCursor struct {
+    PitId string `json:"pit"`
+    SearchAfter []interface{} `json:"after"`
+}
+
+// Paginate returns a PIT paginator over the documents matched by query. The query "sort" gets
+// a _shard_doc tiebreaker, keepAlive defaults to DefaultScrollKeepAlive and pageSize <= 0 keeps the query "size".
+// An optional cursor from Paginator.Cursor resumes a previous walk on its point in time
+func (i *doc) Paginate(query map[string]interface{}, indexName string, keepAlive string, pageSize int, cursor ...string) *Paginator {
+    if keepAlive == "" {
+        keepAlive = DefaultScrollKeepAlive
+    }
+
+    // copied, so the caller's query is left as it is
+    body := make(map[string]interface{}, len(query) + 4)
+    for k, v := range query {
+        body[k] = v
+    }
+    if pageSize > 0 {
+        body["size"] = pageSize
+    }
+    body["sort"] = withTiebreaker(body["sort"])
+
+    p := &Paginator{
+        doc: i,
+        query: body,
+        indexName: indexName,
+        keepAlive: keepAlive,
+        pageSize: toInt(body["size"]),
+    }
+
+    if len(cursor) > 0 && cursor[0] != "" {
+        state, err := decodeCursor(cursor[0])
+        if err != nil {
+            p.cursorErr = err
+        } else {
+            p.pitId = state.PitId
+            p.searchAfter = state.SearchAfter
+        }
+    }
+
+    return p
+}
+
+// withTiebreaker appends the _shard_doc sort, so every hit has a unique search_after position
+func withTiebreaker(sort interface{}) []interface{} {
+    var res []interface{}
+
+    switch v := sort.(type) {
+    case nil:
+    case []interface{}:
+        res = append(res, v...)
+    case []map[string]interface{}:
+        for _, item := range v {
+            res = append(res, item)
+        }
+    case []string:
+        for _, item := range v {
+            res = append(res, item)
+        }
+    default:
+        res = append(res, v)
+    }
+
+    for _, item := range res {
+        if item == "_shard_doc" {
+            return res
+        }
+        if m, ok := item.(map[string]interface{}); ok {
+            if _, ok := m["_shard_doc"]; ok {
+                return res
+            }
+        }
+    }
+
+    return append(res, map[string]interface{}{"_shard_doc": "asc"})
+}
+
+func decodeCursor(cursor string) (paginatorCursor, error) {
+    var state paginatorCursor
+
+    raw, err := base64.RawURLEncoding.DecodeString(cursor)
+    if err != nil {
+        return state, errors.New(fmt.Sprintf("Invalid elastic cursor: %v", err))
+    }
+
+    d := json.NewDecoder(bytes.NewReader(raw))
+    // search_after values must be sent back exactly
+    d.UseNumber()
+    if err := d.Decode(&state); err != nil || state.PitId == "" {
+        return state, errors.New(fmt.Sprintf("Invalid elastic cursor: %s", cursor))
+    }
+
+    return state, nil
+}
+
+// Next returns the next page, io.EOF once every document has been returned.
+// The point in time is closed as soon as the last page is reached
+func (p *Paginator) Next() (SearchResult, error) {
+    if p.cursorErr != nil {
+        return SearchResult{}, p.cursorErr
+    }
+
+    if p.done {
+        return SearchResult{}, io.EOF
+    }
+
+    if p.lastPage {
+        err := p.Close()
+        if err != nil {
+            return SearchResult{}, err
+        }
+
+        return SearchResult{}, io.EOF
+    }
+
+    if err := p.doc.ctx.Err(); err != nil {
+        return SearchResult{}, &ContextError{MethodPost, "/_search", err}
+    }
+
+    if p.pitId == "" {
+        err := p.open()
+        if err != nil {
+            return SearchResult{}, err
+        }
+    }
+
+    body := make(map[string]interface{}, len(p.query) + 2)
+    for k, v := range p.query {
+        body[k] = v
+    }
+    body["pit"] = map[string]interface{}{"id": p.pitId, "keep_alive": p.keepAlive}
+    if p.searchAfter != nil {
+        body["search_after"] = p.searchAfter
+    }
+
+    queryJson, err := toJson(body)
+    if err != nil {
+        return SearchResult{}, errors.New(fmt.Sprintf("Failed to json elastic query: %v", err))
+    }
+
+    // the index comes from the point in time
+    result, err := p.doc.client.RequestCtx(p.doc.ctx, MethodPost, "/_search", queryJson)
+    if err != nil {
+        return SearchResult{}, fmt.Errorf("Failed to paginate elastic index: %w", err)
+    }
+
+    page, err := parseSearchResult(result)
+    if err != nil {
+        return SearchResult{}, err
+    }
+
+    // the id may change between requests
+    if pitId, ok := result["pit_id"].(string); ok {
+        p.pitId = pitId
+    }
+
+    if len(page.Hits) == 0 {
+        p.lastPage = true
+        return p.Next()
+    }
+
+    p.searchAfter = page.Hits[len(page.Hits) - 1].Sort
+    if p.pageSize > 0 && len(page.Hits) < p.pageSize {
+        p.lastPage = true
+    }
+
+    return page, nil
+}
+
+func (p *Paginator) open() error {
+    endpoint := "/"+p.indexName+"/_pit?keep_alive="+url.QueryEscape(p.keepAlive)
+    result, err := p.doc.client.RequestCtx(p.doc.ctx, MethodPost, endpoint, "")
+    if err != nil {
+        return fmt.Errorf("Failed to open elastic point in time: %w", err)
+    }
+
+    pitId, ok := result["id"].(string); if !ok {
+        return errors.New(fmt.Sprintf("Unknown error at Paginate: %v", result))
+    }
+    p.pitId = pitId
+
+    return nil
+}
+
+// Cursor returns a token to resume from the current position with Paginate.
+// It is empty before the first page and after the last one
+func (p *Paginator) Cursor() (string, error) {
+    if p.done || p.lastPage || p.pitId == "" || p.searchAfter == nil {
+        return "", nil
+    }
+
+    state, err := json.Marshal(paginatorCursor{p.pitId, p.searchAfter})
+    if err != nil {
+        return "", errors.New(fmt.Sprintf("Failed to json elastic cursor: %v", err))
+    }
+
+    return base64.RawURLEncoding.EncodeToString(state), nil
+}
+
+// Close closes the point in time, it is safe to call more than once.
+// Don't call it when a Cursor has been handed out to resume later.
+// With a canceled context the point in time is left to expire after keepAlive
+func (p *Paginator) Close() error {
+    if p.done {
+        return nil
+    }
+    p.done = true
+
+    // nothing to close, or no way to send it
+    if p.pitId == "" || p.doc.ctx.Err() != nil {
+        return nil
+    }
+
+    params, err := toJson(map[string]interface{}{"id": p.pitId})
+    if err != nil {
+        return errors.New(fmt.Sprintf("Failed to json elastic point in time: %v", err))
+    }
+
+    _, err = p.doc.client.RequestCtx(p.doc.ctx, MethodDelete, "/_pit", params)
+    // an expired point in time is already closed
+    if err != nil && !errors.Is(err, ErrNotFound) {
+        return fmt.Errorf("Failed to close elastic point in time: %w", err)
+    }
+
+    return nil
+}
@@ -0,0 +1,334 @@
+package query
+
+// Aggregation is a single aggregation, sub aggregations are nested under "aggs"
+type Aggregation interface {
+    Map() map[string]interface{}
+}
+
+func aggsMap(aggs map[string]Aggregation) map[string]interface{} {
+    res := make(map[string]interface{}, len(aggs))
+    for name, a := range aggs {
+        res[name] = a.Map()
+    }
+
+    return res
+}
+
+// agg holds what every aggregation builder shares
+type agg struct {
+    kind string
+    options map[string]interface{}
+    subAggs map[string]Aggregation
+}
+
+func newAgg(kind string, options map[string]interface{}) agg {
+    return agg{kind: kind, options: options}
+}
+
+func (a *agg) addSubAgg(name string, sub Aggregation) {
+    if a.subAggs == nil {
+        a.subAggs = map[string]Aggregation{}
+    }
+
+    a.subAggs[name] = sub
+}
+
+func (a *agg) Map() map[string]interface{} {
+    body := map[string]interface{}{a.kind: copyMap(a.options)}
+    if len(a.subAggs) > 0 {
+        body["aggs"] = aggsMap(a.subAggs)
+    }
+
+    return body
+}
+
+type TermsAggregation struct {
+    agg
+}
+
+func TermsAgg(field string) *TermsAggregation {
+    return &TermsAggregation{newAgg("terms", map[string]interface{}{"field": field})}
+}
+
+func (a *TermsAggregation) Size(size int) *TermsAggregation {
+    a.options["size"] = size
+    return a
+}
+
+func (a *TermsAggregation) MinDocCount(count int) *TermsAggregation {
+    a.options["min_doc_count"] = count
+    return a
+}
+
+// Order sorts the buckets by key, e.g. "_count", "_key" or a sub aggregation name
+func (a *TermsAggregation) Order(key string, direction string) *TermsAggregation {
+    a.options["order"] = map[string]interface{}{key: direction}
+    return a
+}
+
+func (a *TermsAggregation) Missing(missing interface{}) *TermsAggregation {
+    a.options["missing"] = missing
+    return a
+}
+
+func (a *TermsAggregation) SubAgg(name string, sub Aggregation) *TermsAggregation {
+    a.addSubAgg(name, sub)
+    return a
+}
+
+type DateHistogramAggregation struct {
+    agg
+}
+
+func DateHistogramAgg(field string) *DateHistogramAggregation {
+    return &DateHistogramAggregation{newAgg("date_histogram", map[string]interface{}{"field": field})}
+}
+
+// CalendarInterval is a calendar unit like "day", "1M" or "quarter"
+func (a *DateHistogramAggregation) CalendarInterval(interval string) *DateHistogramAggregation {
+    a.options["calendar_interval"] = interval
+    return a
+}
+
+// FixedInterval is a fixed duration like "30m" or "12h"
+func (a *DateHistogramAggregation) FixedInterval(interval string) *DateHistogramAggregation {
+    a.options["fixed_interval"] = interval
+    return a
+}
+
+func (a *DateHistogramAggregation) Format(format string) *DateHistogramAggregation {
+    a.options["format"] = format
+    return a
+}
+
+func (a *DateHistogramAggregation) TimeZone(timeZone string) *DateHistogramAggregation {
+    a.options["time_zone"] = timeZone
+    return a
+}
+
+func (a *DateHistogramAggregation) MinDocCount(count int) *DateHistogramAggregation {
+    a.options["min_doc_count"] = count
+    return a
+}
+
+// ExtendedBounds returns empty buckets for the whole min..max period
+func (a *DateHistogramAggregation) ExtendedBounds(min interface{}, max interface{}) *DateHistogramAggregation {
+    a.options["extended_bounds"] = map[string]interface{}{"min": min, "max": max}
+    return a
+}
+
+func (a *DateHistogramAggregation) SubAgg(name string, sub Aggregation) *DateHistogramAggregation {
+    a.addSubAgg(name, sub)
+    return a
+}
+
+type HistogramAggregation struct {
+    agg
+}
+
+func HistogramAgg(field string, interval float64) *HistogramAggregation {
+    return &HistogramAggregation{newAgg("histogram", map[string]interface{}{
+        "field": field,
+        "interval": interval,
+    })}
+}
+
+func (a *HistogramAggregation) MinDocCount(count int) *HistogramAggregation {
+    a.options["min_doc_count"] = count
+    return a
+}
+
+func (a *HistogramAggregation) Offset(offset float64) *HistogramAggregation {
+    a.options["offset"] = offset
+    return a
+}
+
+func (a *HistogramAggregation) ExtendedBounds(min float64, max float64) *HistogramAggregation {
+    a.options["extended_bounds"] = map[string]interface{}{"min": min, "max": max}
+    return a
+}
+
+func (a *HistogramAggregation) SubAgg(name string, sub Aggregation) *HistogramAggregation {
+    a.addSubAgg(name, sub)
+    return a
+}
+
+type RangeAggregation struct {
+    agg
+    ranges []map[string]interface{}
+}
+
+func RangeAgg(field string) *RangeAggregation {
+    return &RangeAggregation{agg: newAgg("range", map[string]interface{}{"field": field})}
+}
+
+// AddRange adds the from( inclusive )..to( exclusive ) bucket, nil leaves a side open
+func (a *RangeAggregation) AddRange(from interface{}, to interface{}) *RangeAggregation {
+    return a.AddKeyedRange("", from, to)
+}
+
+// AddKeyedRange is AddRange with a custom bucket key
+func (a *RangeAggregation) AddKeyedRange(key string, from interface{}, to interface{}) *RangeAggregation {
+    r := map[string]interface{}{}
+    if key != "" {
+        r["key"] = key
+    }
+    if from != nil {
+        r["from"] = from
+    }
+    if to != nil {
+        r["to"] = to
+    }
+
+    a.ranges = append(a.ranges, r)
+    return a
+}
+
+func (a *RangeAggregation) SubAgg(name string, sub Aggregation) *RangeAggregation {
+    a.addSubAgg(name, sub)
+    return a
+}
+
+func (a *RangeAggregation) Map() map[string]interface{} {
+    body := a.agg.Map()
+
+    ranges := make([]interface{}, 0, len(a.ranges))
+    for _, r := range a.ranges {
+        ranges = append(ranges, copyMap(r))
+    }
+    body[a.kind].(map[string]interface{})["ranges"] = ranges
+
+    return body
+}
+
+// MetricAggregation is a single field metric: avg, sum, min, max or stats
+type MetricAggregation struct {
+    agg
+}
+
+func AvgAgg(field string) *MetricAggregation {
+    return newMetricAgg("avg", field)
+}
+
+func SumAgg(field string) *MetricAggregation {
+    return newMetricAgg("sum", field)
+}
+
+func MinAgg(field string) *MetricAggregation {
+    return newMetricAgg("min", field)
+}
+
+func MaxAgg(field string) *MetricAggregation {
+    return newMetricAgg("max", field)
+}
+
+// StatsAgg returns count, min, max, avg and sum at once
+func StatsAgg(field string) *MetricAggregation {
+    return newMetricAgg("stats", field)
+}
+
+func newMetricAgg(kind string, field string) *MetricAggregation {
+    return &MetricAggregation{newAgg(kind, map[string]interface{}{"field": field})}
+}
+
+// Missing is the value used for documents without the field
+func (a *MetricAggregation) Missing(missing interface{}) *MetricAggregation {
+    a.options["missing"] = missing
+    return a
+}
+
+type CardinalityAggregation struct {
+    agg
+}
+
+func CardinalityAgg(field string) *CardinalityAggregation {
+    return &CardinalityAggregation{newAgg("cardinality", map[string]interface{}{"field": field})}
+}
+
+func (a *CardinalityAggregation) PrecisionThreshold(threshold int) *CardinalityAggregation {
+    a.options["precision_threshold"] = threshold
+    return a
+}
+
+type PercentilesAggregation struct {
+    agg
+}
+
+// PercentilesAgg uses the elastic default percents when none are given
+func PercentilesAgg(field string, percents ...float64) *PercentilesAggregation {
+    options := map[string]interface{}{"field": field}
+    if len(percents) > 0 {
+        options["percents"] = percents
+    }
+
+    return &PercentilesAggregation{newAgg("percentiles", options)}
+}
+
+type TopHitsAggregation struct {
+    agg
+    sort []interface{}
+}
+
+func TopHitsAgg() *TopHitsAggregation {
+    return &TopHitsAggregation{agg: newAgg("top_hits", map[string]interface{}{})}
+}
+
+func (a *TopHitsAggregation) Size(size int) *TopHitsAggregation {
+    a.options["size"] = size
+    return a
+}
+
+func (a *TopHitsAggregation) Sort(field string, order string) *TopHitsAggregation {
+    a.sort = append(a.sort, map[string]interface{}{
+        field: map[string]interface{}{"order": order},
+    })
+    return a
+}
+
+func (a *TopHitsAggregation) Source(includes ...string) *TopHitsAggregation {
+    a.options["_source"] = includes
+    return a
+}
+
+func (a *TopHitsAggregation) Map() map[string]interface{} {
+    body := a.agg.Map()
+    if len(a.sort) > 0 {
+        body[a.kind].(map[string]interface{})["sort"] = a.sort
+    }
+
+    return body
+}
+
+type NestedAggregation struct {
+    agg
+}
+
+// NestedAgg runs its sub aggregations over the nested documents at path
+func NestedAgg(path string) *NestedAggregation {
+    return &NestedAggregation{newAgg("nested", map[string]interface{}{"path": path})}
+}
+
+func (a *NestedAggregation) SubAgg(name string, sub Aggregation) *NestedAggregation {
+    a.addSubAgg(name, sub)
+    return a
+}
+
+type ReverseNestedAggregation struct {
+    agg
+}
+
+// ReverseNestedAgg joins back from nested documents to the root ones
+func ReverseNestedAgg() *ReverseNestedAggregation {
+    return &ReverseNestedAggregation{newAgg("reverse_nested", map[string]interface{}{})}
+}
+
+// Path joins back to a parent nested level instead of the root
+func (a *ReverseNestedAggregation) Path(path string) *ReverseNestedAggregation {
+    a.options["path"] = path
+    return a
+}
+
+func (a *ReverseNestedAggregation) SubAgg(name string, sub Aggregation) *ReverseNestedAggregation {
+    a.addSubAgg(name, sub)
+    return a
+}
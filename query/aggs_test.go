@@ -0,0 +1,61 @@
+package query
+
+import (
+    "testing"
+)
+
+func TestAggregations(t *testing.T) {
+    cases := []struct {
+        name string
+        agg Aggregation
+    }{
+        {"agg_terms", TermsAgg("city").Size(5).MinDocCount(1).Order("_count", "desc").Missing("n/a")},
+        {"agg_terms_sub", TermsAgg("city").SubAgg("avg_price", AvgAgg("price")).SubAgg("per_day", DateHistogramAgg("created").CalendarInterval("day"))},
+        {"agg_date_histogram", DateHistogramAgg("created").
+            FixedInterval("12h").
+            Format("yyyy-MM-dd").
+            TimeZone("Europe/Berlin").
+            MinDocCount(0).
+            ExtendedBounds("2024-01-01", "2024-01-31")},
+        {"agg_histogram", HistogramAgg("price", 50).Offset(10).MinDocCount(0).ExtendedBounds(0, 500).SubAgg("max_price", MaxAgg("price"))},
+        {"agg_range", RangeAgg("price").AddRange(nil, 100).AddRange(100, 200).AddKeyedRange("expensive", 200, nil)},
+        {"agg_avg", AvgAgg("price").Missing(0)},
+        {"agg_sum", SumAgg("price")},
+        {"agg_min", MinAgg("price")},
+        {"agg_max", MaxAgg("price")},
+        {"agg_stats", StatsAgg("price")},
+        {"agg_cardinality", CardinalityAgg("user_id").PrecisionThreshold(1000)},
+        {"agg_percentiles", PercentilesAgg("latency", 50, 95, 99.9)},
+        {"agg_percentiles_default", PercentilesAgg("latency")},
+        {"agg_top_hits", TopHitsAgg().Size(3).Sort("created", "desc").Source("title")},
+        {"agg_nested", NestedAgg("comments").
+            SubAgg("by_author", TermsAgg("comments.author").
+                SubAgg("posts", ReverseNestedAgg().SubAgg("tags", TermsAgg("tags"))))},
+        {"agg_reverse_nested_path", ReverseNestedAgg().Path("comments")},
+    }
+
+    for _, c := range cases {
+        t.Run(c.name, func(t *testing.T) {
+            assertGolden(t, c.name, c.agg.Map())
+        })
+    }
+}
+
+func TestSearchSourceAggregations(t *testing.T) {
+    search := NewSearch().
+        Query(Range("created").Gte("now-7d")).
+        Size(0).
+        Aggregation("per_day", DateHistogramAgg("created").CalendarInterval("day").SubAgg("revenue", SumAgg("price"))).
+        Aggregation("by_city", TermsAgg("city").Size(10))
+
+    assertGolden(t, "search_aggs", search.Map())
+}
+
+func TestRangeAggMapIsRepeatable(t *testing.T) {
+    a := RangeAgg("price").AddRange(nil, 100)
+    a.Map()["range"].(map[string]interface{})["ranges"].([]interface{})[0].(map[string]interface{})["from"] = 1
+
+    _, ok := a.Map()["range"].(map[string]interface{})["ranges"].([]interface{})[0].(map[string]interface{})["from"]; if ok {
+        t.Errorf("RangeAgg Map() result shares state with the builder")
+    }
+}
@@ -0,0 +1,203 @@
+package query
+
+// Function is a single function_score function
+type Function interface {
+    Map() map[string]interface{}
+}
+
+type FunctionScoreQuery struct {
+    query Query
+    functions []map[string]interface{}
+    options map[string]interface{}
+}
+
+// FunctionScore rescores the documents matched by q, a nil q matches all
+func FunctionScore(q Query) *FunctionScoreQuery {
+    return &FunctionScoreQuery{query: q, options: map[string]interface{}{}}
+}
+
+// Add applies fn to the documents matching filter, a nil filter applies it to all
+func (q *FunctionScoreQuery) Add(filter Query, fn Function) *FunctionScoreQuery {
+    body := fn.Map()
+    if filter != nil {
+        body["filter"] = filter.Map()
+    }
+
+    q.functions = append(q.functions, body)
+    return q
+}
+
+// ScoreMode is one of multiply, sum, avg, first, max or min
+func (q *FunctionScoreQuery) ScoreMode(scoreMode string) *FunctionScoreQuery {
+    q.options["score_mode"] = scoreMode
+    return q
+}
+
+// BoostMode is one of multiply, replace, sum, avg, max or min
+func (q *FunctionScoreQuery) BoostMode(boostMode string) *FunctionScoreQuery {
+    q.options["boost_mode"] = boostMode
+    return q
+}
+
+func (q *FunctionScoreQuery) MaxBoost(maxBoost float64) *FunctionScoreQuery {
+    q.options["max_boost"] = maxBoost
+    return q
+}
+
+func (q *FunctionScoreQuery) MinScore(minScore float64) *FunctionScoreQuery {
+    q.options["min_score"] = minScore
+    return q
+}
+
+func (q *FunctionScoreQuery) Boost(boost float64) *FunctionScoreQuery {
+    q.options["boost"] = boost
+    return q
+}
+
+func (q *FunctionScoreQuery) Map() map[string]interface{} {
+    body := copyMap(q.options)
+
+    if q.query != nil {
+        body["query"] = q.query.Map()
+    }
+
+    if len(q.functions) > 0 {
+        functions := make([]interface{}, 0, len(q.functions))
+        for _, fn := range q.functions {
+            functions = append(functions, fn)
+        }
+        body["functions"] = functions
+    }
+
+    return map[string]interface{}{"function_score": body}
+}
+
+type WeightFunction struct {
+    weight float64
+}
+
+func Weight(weight float64) *WeightFunction {
+    return &WeightFunction{weight}
+}
+
+func (f *WeightFunction) Map() map[string]interface{} {
+    return map[string]interface{}{"weight": f.weight}
+}
+
+type FieldValueFactorFunction struct {
+    options map[string]interface{}
+    weight *float64
+}
+
+func FieldValueFactor(field string) *FieldValueFactorFunction {
+    return &FieldValueFactorFunction{options: map[string]interface{}{"field": field}}
+}
+
+func (f *FieldValueFactorFunction) Factor(factor float64) *FieldValueFactorFunction {
+    f.options["factor"] = factor
+    return f
+}
+
+// Modifier is one of none, log, log1p, log2p, ln, ln1p, ln2p, square, sqrt or reciprocal
+func (f *FieldValueFactorFunction) Modifier(modifier string) *FieldValueFactorFunction {
+    f.options["modifier"] = modifier
+    return f
+}
+
+func (f *FieldValueFactorFunction) Missing(missing float64) *FieldValueFactorFunction {
+    f.options["missing"] = missing
+    return f
+}
+
+func (f *FieldValueFactorFunction) Weight(weight float64) *FieldValueFactorFunction {
+    f.weight = &weight
+    return f
+}
+
+func (f *FieldValueFactorFunction) Map() map[string]interface{} {
+    body := map[string]interface{}{"field_value_factor": copyMap(f.options)}
+    if f.weight != nil {
+        body["weight"] = *f.weight
+    }
+
+    return body
+}
+
+type RandomScoreFunction struct {
+    options map[string]interface{}
+}
+
+// RandomScore scores randomly, seed and field make the scores reproducible
+func RandomScore() *RandomScoreFunction {
+    return &RandomScoreFunction{map[string]interface{}{}}
+}
+
+func (f *RandomScoreFunction) Seed(seed interface{}, field string) *RandomScoreFunction {
+    f.options["seed"] = seed
+    f.options["field"] = field
+    return f
+}
+
+func (f *RandomScoreFunction) Map() map[string]interface{} {
+    return map[string]interface{}{"random_score": copyMap(f.options)}
+}
+
+type DecayFunction struct {
+    kind string
+    field string
+    options map[string]interface{}
+    weight *float64
+}
+
+// Decay builds a gauss, linear or exp decay function around origin
+func Decay(kind string, field string, origin interface{}, scale interface{}) *DecayFunction {
+    return &DecayFunction{kind, field, map[string]interface{}{
+        "origin": origin,
+        "scale": scale,
+    }, nil}
+}
+
+func (f *DecayFunction) Offset(offset interface{}) *DecayFunction {
+    f.options["offset"] = offset
+    return f
+}
+
+func (f *DecayFunction) DecayValue(decay float64) *DecayFunction {
+    f.options["decay"] = decay
+    return f
+}
+
+func (f *DecayFunction) Weight(weight float64) *DecayFunction {
+    f.weight = &weight
+    return f
+}
+
+func (f *DecayFunction) Map() map[string]interface{} {
+    body := map[string]interface{}{
+        f.kind: map[string]interface{}{f.field: copyMap(f.options)},
+    }
+    if f.weight != nil {
+        body["weight"] = *f.weight
+    }
+
+    return body
+}
+
+type ScriptScoreFunction struct {
+    script map[string]interface{}
+}
+
+func ScriptScore(source string, params map[string]interface{}) *ScriptScoreFunction {
+    script := map[string]interface{}{"source": source}
+    if len(params) > 0 {
+        script["params"] = params
+    }
+
+    return &ScriptScoreFunction{script}
+}
+
+func (f *ScriptScoreFunction) Map() map[string]interface{} {
+    return map[string]interface{}{
+        "script_score": map[string]interface{}{"script": f.script},
+    }
+}
@@ -0,0 +1,450 @@
+// Package query builds elastic search queries which serialize
+// to the maps accepted by elastic.Doc.Search
+package query
+
+// Query is a single query clause
+type Query interface {
+    Map() map[string]interface{}
+}
+
+func maps(queries []Query) []interface{} {
+    list := make([]interface{}, 0, len(queries))
+    for _, q := range queries {
+        list = append(list, q.Map())
+    }
+
+    return list
+}
+
+// copyMap returns a shallow copy, so maps built by Map() don't share
+// state with the builder
+func copyMap(m map[string]interface{}) map[string]interface{} {
+    res := make(map[string]interface{}, len(m))
+    for k, v := range m {
+        res[k] = v
+    }
+
+    return res
+}
+
+// withOptions puts the leaf value into options when any option is set,
+// so `{"term": {"field": "value"}}` only grows into the long form when needed
+func withOptions(valueKey string, value interface{}, options map[string]interface{}) interface{} {
+    if len(options) == 0 {
+        return value
+    }
+
+    body := copyMap(options)
+    body[valueKey] = value
+
+    return body
+}
+
+type MatchAllQuery struct {
+    options map[string]interface{}
+}
+
+func MatchAll() *MatchAllQuery {
+    return &MatchAllQuery{map[string]interface{}{}}
+}
+
+func (q *MatchAllQuery) Boost(boost float64) *MatchAllQuery {
+    q.options["boost"] = boost
+    return q
+}
+
+func (q *MatchAllQuery) Map() map[string]interface{} {
+    return map[string]interface{}{"match_all": copyMap(q.options)}
+}
+
+type BoolQuery struct {
+    must []Query
+    should []Query
+    filter []Query
+    mustNot []Query
+    options map[string]interface{}
+}
+
+func Bool() *BoolQuery {
+    return &BoolQuery{options: map[string]interface{}{}}
+}
+
+func (q *BoolQuery) Must(queries ...Query) *BoolQuery {
+    q.must = append(q.must, queries...)
+    return q
+}
+
+func (q *BoolQuery) Should(queries ...Query) *BoolQuery {
+    q.should = append(q.should, queries...)
+    return q
+}
+
+func (q *BoolQuery) Filter(queries ...Query) *BoolQuery {
+    q.filter = append(q.filter, queries...)
+    return q
+}
+
+func (q *BoolQuery) MustNot(queries ...Query) *BoolQuery {
+    q.mustNot = append(q.mustNot, queries...)
+    return q
+}
+
+// MinimumShouldMatch accepts a number or a string like "75%"
+func (q *BoolQuery) MinimumShouldMatch(value interface{}) *BoolQuery {
+    q.options["minimum_should_match"] = value
+    return q
+}
+
+func (q *BoolQuery) Boost(boost float64) *BoolQuery {
+    q.options["boost"] = boost
+    return q
+}
+
+func (q *BoolQuery) Map() map[string]interface{} {
+    body := copyMap(q.options)
+
+    clauses := []struct {
+        name string
+        queries []Query
+    }{
+        {"must", q.must},
+        {"should", q.should},
+        {"filter", q.filter},
+        {"must_not", q.mustNot},
+    }
+    for _, clause := range clauses {
+        if len(clause.queries) > 0 {
+            body[clause.name] = maps(clause.queries)
+        }
+    }
+
+    return map[string]interface{}{"bool": body}
+}
+
+type TermQuery struct {
+    field string
+    value interface{}
+    options map[string]interface{}
+}
+
+func Term(field string, value interface{}) *TermQuery {
+    return &TermQuery{field, value, map[string]interface{}{}}
+}
+
+func (q *TermQuery) Boost(boost float64) *TermQuery {
+    q.options["boost"] = boost
+    return q
+}
+
+func (q *TermQuery) CaseInsensitive(caseInsensitive bool) *TermQuery {
+    q.options["case_insensitive"] = caseInsensitive
+    return q
+}
+
+func (q *TermQuery) Map() map[string]interface{} {
+    return map[string]interface{}{
+        "term": map[string]interface{}{
+            q.field: withOptions("value", q.value, q.options),
+        },
+    }
+}
+
+type TermsQuery struct {
+    field string
+    values []interface{}
+    options map[string]interface{}
+}
+
+func Terms(field string, values ...interface{}) *TermsQuery {
+    return &TermsQuery{field, values, map[string]interface{}{}}
+}
+
+func (q *TermsQuery) Boost(boost float64) *TermsQuery {
+    q.options["boost"] = boost
+    return q
+}
+
+func (q *TermsQuery) Map() map[string]interface{} {
+    values := q.values
+    if values == nil {
+        values = []interface{}{}
+    }
+
+    body := copyMap(q.options)
+    body[q.field] = values
+
+    return map[string]interface{}{"terms": body}
+}
+
+type MatchQuery struct {
+    field string
+    text interface{}
+    options map[string]interface{}
+}
+
+func Match(field string, text interface{}) *MatchQuery {
+    return &MatchQuery{field, text, map[string]interface{}{}}
+}
+
+// Operator is "or"( default ) or "and"
+func (q *MatchQuery) Operator(operator string) *MatchQuery {
+    q.options["operator"] = operator
+    return q
+}
+
+// Fuzziness accepts "AUTO" or an edit distance
+func (q *MatchQuery) Fuzziness(fuzziness interface{}) *MatchQuery {
+    q.options["fuzziness"] = fuzziness
+    return q
+}
+
+func (q *MatchQuery) MinimumShouldMatch(value interface{}) *MatchQuery {
+    q.options["minimum_should_match"] = value
+    return q
+}
+
+func (q *MatchQuery) Analyzer(analyzer string) *MatchQuery {
+    q.options["analyzer"] = analyzer
+    return q
+}
+
+func (q *MatchQuery) Boost(boost float64) *MatchQuery {
+    q.options["boost"] = boost
+    return q
+}
+
+func (q *MatchQuery) Map() map[string]interface{} {
+    return map[string]interface{}{
+        "match": map[string]interface{}{
+            q.field: withOptions("query", q.text, q.options),
+        },
+    }
+}
+
+type MultiMatchQuery struct {
+    options map[string]interface{}
+}
+
+// MultiMatch searches text in fields, which may carry boosts like "name^2"
+func MultiMatch(text interface{}, fields ...string) *MultiMatchQuery {
+    return &MultiMatchQuery{map[string]interface{}{
+        "query": text,
+        "fields": fields,
+    }}
+}
+
+// Type is one of best_fields, most_fields, cross_fields, phrase, phrase_prefix or bool_prefix
+func (q *MultiMatchQuery) Type(matchType string) *MultiMatchQuery {
+    q.options["type"] = matchType
+    return q
+}
+
+func (q *MultiMatchQuery) Operator(operator string) *MultiMatchQuery {
+    q.options["operator"] = operator
+    return q
+}
+
+func (q *MultiMatchQuery) Fuzziness(fuzziness interface{}) *MultiMatchQuery {
+    q.options["fuzziness"] = fuzziness
+    return q
+}
+
+func (q *MultiMatchQuery) TieBreaker(tieBreaker float64) *MultiMatchQuery {
+    q.options["tie_breaker"] = tieBreaker
+    return q
+}
+
+func (q *MultiMatchQuery) Boost(boost float64) *MultiMatchQuery {
+    q.options["boost"] = boost
+    return q
+}
+
+func (q *MultiMatchQuery) Map() map[string]interface{} {
+    return map[string]interface{}{"multi_match": copyMap(q.options)}
+}
+
+type RangeQuery struct {
+    field string
+    options map[string]interface{}
+}
+
+func Range(field string) *RangeQuery {
+    return &RangeQuery{field, map[string]interface{}{}}
+}
+
+func (q *RangeQuery) Gt(value interface{}) *RangeQuery {
+    q.options["gt"] = value
+    return q
+}
+
+func (q *RangeQuery) Gte(value interface{}) *RangeQuery {
+    q.options["gte"] = value
+    return q
+}
+
+func (q *RangeQuery) Lt(value interface{}) *RangeQuery {
+    q.options["lt"] = value
+    return q
+}
+
+func (q *RangeQuery) Lte(value interface{}) *RangeQuery {
+    q.options["lte"] = value
+    return q
+}
+
+// Format is the date format of the bounds, e.g. "yyyy-MM-dd"
+func (q *RangeQuery) Format(format string) *RangeQuery {
+    q.options["format"] = format
+    return q
+}
+
+func (q *RangeQuery) TimeZone(timeZone string) *RangeQuery {
+    q.options["time_zone"] = timeZone
+    return q
+}
+
+func (q *RangeQuery) Boost(boost float64) *RangeQuery {
+    q.options["boost"] = boost
+    return q
+}
+
+func (q *RangeQuery) Map() map[string]interface{} {
+    return map[string]interface{}{
+        "range": map[string]interface{}{
+            q.field: copyMap(q.options),
+        },
+    }
+}
+
+type ExistsQuery struct {
+    field string
+}
+
+func Exists(field string) *ExistsQuery {
+    return &ExistsQuery{field}
+}
+
+func (q *ExistsQuery) Map() map[string]interface{} {
+    return map[string]interface{}{
+        "exists": map[string]interface{}{"field": q.field},
+    }
+}
+
+type PrefixQuery struct {
+    field string
+    value string
+    options map[string]interface{}
+}
+
+func Prefix(field string, value string) *PrefixQuery {
+    return &PrefixQuery{field, value, map[string]interface{}{}}
+}
+
+func (q *PrefixQuery) CaseInsensitive(caseInsensitive bool) *PrefixQuery {
+    q.options["case_insensitive"] = caseInsensitive
+    return q
+}
+
+func (q *PrefixQuery) Boost(boost float64) *PrefixQuery {
+    q.options["boost"] = boost
+    return q
+}
+
+func (q *PrefixQuery) Map() map[string]interface{} {
+    return map[string]interface{}{
+        "prefix": map[string]interface{}{
+            q.field: withOptions("value", q.value, q.options),
+        },
+    }
+}
+
+type WildcardQuery struct {
+    field string
+    pattern string
+    options map[string]interface{}
+}
+
+// Wildcard matches pattern with * and ? placeholders
+func Wildcard(field string, pattern string) *WildcardQuery {
+    return &WildcardQuery{field, pattern, map[string]interface{}{}}
+}
+
+func (q *WildcardQuery) CaseInsensitive(caseInsensitive bool) *WildcardQuery {
+    q.options["case_insensitive"] = caseInsensitive
+    return q
+}
+
+func (q *WildcardQuery) Boost(boost float64) *WildcardQuery {
+    q.options["boost"] = boost
+    return q
+}
+
+func (q *WildcardQuery) Map() map[string]interface{} {
+    return map[string]interface{}{
+        "wildcard": map[string]interface{}{
+            q.field: withOptions("value", q.pattern, q.options),
+        },
+    }
+}
+
+type NestedQuery struct {
+    query Query
+    options map[string]interface{}
+}
+
+// Nested matches the documents whose nested objects at path match q, a nil q matches all
+func Nested(path string, q Query) *NestedQuery {
+    return &NestedQuery{q, map[string]interface{}{"path": path}}
+}
+
+// ScoreMode is one of avg, max, min, none or sum
+func (q *NestedQuery) ScoreMode(scoreMode string) *NestedQuery {
+    q.options["score_mode"] = scoreMode
+    return q
+}
+
+// InnerHits returns the matching nested documents, options may be nil
+func (q *NestedQuery) InnerHits(options map[string]interface{}) *NestedQuery {
+    if options == nil {
+        options = map[string]interface{}{}
+    }
+
+    q.options["inner_hits"] = options
+    return q
+}
+
+func (q *NestedQuery) IgnoreUnmapped(ignoreUnmapped bool) *NestedQuery {
+    q.options["ignore_unmapped"] = ignoreUnmapped
+    return q
+}
+
+func (q *NestedQuery) Map() map[string]interface{} {
+    body := copyMap(q.options)
+
+    if q.query != nil {
+        body["query"] = q.query.Map()
+    } else {
+        body["query"] = MatchAll().Map()
+    }
+
+    return map[string]interface{}{"nested": body}
+}
+
+type IDsQuery struct {
+    ids []string
+}
+
+func IDs(ids ...string) *IDsQuery {
+    return &IDsQuery{ids}
+}
+
+func (q *IDsQuery) Map() map[string]interface{} {
+    ids := q.ids
+    if ids == nil {
+        ids = []string{}
+    }
+
+    return map[string]interface{}{
+        "ids": map[string]interface{}{"values": ids},
+    }
+}
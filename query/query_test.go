@@ -0,0 +1,132 @@
+package query
+
+import (
+    "bytes"
+    "encoding/json"
+    "flag"
+    "io/ioutil"
+    "path/filepath"
+    "testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// assertGolden compares the indented json of m with testdata/<name>.json
+func assertGolden(t *testing.T, name string, m map[string]interface{}) {
+    t.Helper()
+
+    got, err := json.MarshalIndent(m, "", "  ")
+    if err != nil {
+        t.Fatalf("Failed to json %s: %v", name, err)
+    }
+    got = append(got, '\n')
+
+    path := filepath.Join("testdata", name + ".json")
+    if *update {
+        if err := ioutil.WriteFile(path, got, 0644); err != nil {
+            t.Fatalf("Failed to write %s: %v", path, err)
+        }
+    }
+
+    want, err := ioutil.ReadFile(path)
+    if err != nil {
+        t.Fatalf("Failed to read %s: %v", path, err)
+    }
+
+    if !bytes.Equal(got, want) {
+        t.Errorf("%s mismatch\ngot:\n%s\nwant:\n%s", name, got, want)
+    }
+}
+
+func TestQueries(t *testing.T) {
+    cases := []struct {
+        name string
+        query Query
+    }{
+        {"match_all", MatchAll()},
+        {"term", Term("status", "active")},
+        {"term_options", Term("status", "Active").Boost(2).CaseInsensitive(true)},
+        {"terms", Terms("tags", "go", "elastic").Boost(1.5)},
+        {"terms_empty", Terms("tags")},
+        {"match", Match("title", "quick fox")},
+        {"match_options", Match("title", "quick fox").Operator("and").Fuzziness("AUTO").MinimumShouldMatch("75%")},
+        {"multi_match", MultiMatch("quick fox", "title^2", "body").Type("best_fields").TieBreaker(0.3)},
+        {"range", Range("created").Gte("2023-01-01").Lt("2024-01-01").Format("yyyy-MM-dd").TimeZone("+01:00")},
+        {"exists", Exists("email")},
+        {"prefix", Prefix("name", "jo")},
+        {"prefix_options", Prefix("name", "Jo").CaseInsensitive(true)},
+        {"wildcard", Wildcard("name", "jo*n?")},
+        {"nested", Nested("comments", Match("comments.text", "great")).ScoreMode("max").InnerHits(nil)},
+        {"nested_no_query", Nested("comments", nil)},
+        {"ids", IDs("1", "2", "3")},
+        {"ids_empty", IDs()},
+        {"bool", Bool().
+            Must(Match("title", "go")).
+            Should(Term("tags", "elastic"), Term("tags", "search")).
+            Filter(Range("year").Gte(2020)).
+            MustNot(Exists("deleted_at")).
+            MinimumShouldMatch(1)},
+        {"bool_empty", Bool()},
+        {"bool_nested", Bool().Filter(
+            Bool().Should(IDs("1"), Prefix("name", "a")),
+            Nested("authors", Term("authors.name", "ann")),
+        )},
+        {"function_score", FunctionScore(Match("title", "go")).
+            Add(nil, FieldValueFactor("likes").Factor(1.2).Modifier("log1p").Missing(1)).
+            Add(Term("featured", true), Weight(3)).
+            Add(nil, Decay("gauss", "created", "now", "10d").Offset("1d").DecayValue(0.5)).
+            Add(nil, RandomScore().Seed(42, "_seq_no")).
+            Add(nil, ScriptScore("doc['rank'].value * params.f", map[string]interface{}{"f": 2})).
+            ScoreMode("sum").
+            BoostMode("multiply").
+            MaxBoost(10)},
+        {"function_score_no_query", FunctionScore(nil).Add(nil, Weight(2))},
+    }
+
+    for _, c := range cases {
+        t.Run(c.name, func(t *testing.T) {
+            assertGolden(t, c.name, c.query.Map())
+        })
+    }
+}
+
+func TestSearchSource(t *testing.T) {
+    search := NewSearch().
+        Query(Bool().Must(Match("title", "go")).Filter(Term("status", "active"))).
+        From(20).
+        Size(10).
+        Sort("created", "desc").
+        Sort("_score", "desc").
+        Source("title", "created").
+        TrackTotalHits(true)
+
+    assertGolden(t, "search", search.Map())
+}
+
+func TestMapIsRepeatable(t *testing.T) {
+    q := Bool().Must(Term("a", 1))
+    first := q.Map()
+    first["bool"].(map[string]interface{})["boost"] = 5
+
+    _, ok := q.Map()["bool"].(map[string]interface{})["boost"]; if ok {
+        t.Errorf("Map() result shares state with the builder")
+    }
+
+    r := Range("year").Gte(2020)
+    r.Map()["range"].(map[string]interface{})["year"].(map[string]interface{})["lt"] = 2030
+
+    _, ok = r.Map()["range"].(map[string]interface{})["year"].(map[string]interface{})["lt"]; if ok {
+        t.Errorf("Range Map() result shares state with the builder")
+    }
+}
+
+func TestNestedMapsLazily(t *testing.T) {
+    inner := Bool().Must(Term("comments.author", "ann"))
+    q := Nested("comments", inner)
+    inner.Filter(Term("comments.status", "approved"))
+
+    body := q.Map()["nested"].(map[string]interface{})["query"].(map[string]interface{})["bool"].(map[string]interface{})
+    if _, ok := body["filter"]; !ok {
+        t.Errorf("Nested lost the later changes of its query: %v", body)
+    }
+}
@@ -0,0 +1,75 @@
+package query
+
+// SearchSource is the whole body of a _search request
+type SearchSource struct {
+    query Query
+    sort []interface{}
+    aggs map[string]Aggregation
+    options map[string]interface{}
+}
+
+func NewSearch() *SearchSource {
+    return &SearchSource{options: map[string]interface{}{}}
+}
+
+func (s *SearchSource) Query(q Query) *SearchSource {
+    s.query = q
+    return s
+}
+
+func (s *SearchSource) From(from int) *SearchSource {
+    s.options["from"] = from
+    return s
+}
+
+func (s *SearchSource) Size(size int) *SearchSource {
+    s.options["size"] = size
+    return s
+}
+
+// Sort adds a sort field, order is "asc" or "desc"
+func (s *SearchSource) Sort(field string, order string) *SearchSource {
+    s.sort = append(s.sort, map[string]interface{}{
+        field: map[string]interface{}{"order": order},
+    })
+    return s
+}
+
+// Source limits the returned _source fields
+func (s *SearchSource) Source(includes ...string) *SearchSource {
+    s.options["_source"] = includes
+    return s
+}
+
+// Aggregation adds a named top level aggregation
+func (s *SearchSource) Aggregation(name string, a Aggregation) *SearchSource {
+    if s.aggs == nil {
+        s.aggs = map[string]Aggregation{}
+    }
+
+    s.aggs[name] = a
+    return s
+}
+
+func (s *SearchSource) TrackTotalHits(track interface{}) *SearchSource {
+    s.options["track_total_hits"] = track
+    return s
+}
+
+func (s *SearchSource) Map() map[string]interface{} {
+    body := copyMap(s.options)
+
+    if s.query != nil {
+        body["query"] = s.query.Map()
+    }
+
+    if len(s.sort) > 0 {
+        body["sort"] = s.sort
+    }
+
+    if len(s.aggs) > 0 {
+        body["aggs"] = aggsMap(s.aggs)
+    }
+
+    return body
+}
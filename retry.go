@@ -0,0 +1,220 @@
+package elastic
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "math/rand"
+    "time"
+)
+
+func (p RetryPolicy) maxAttempts() int {
+    if p.MaxAttempts <= 0 {
+        return DefaultRetryMaxAttempts
+    }
+
+    return p.MaxAttempts
+}
+
+// backoff returns the full-jitter delay before the given retry( 1-based )
+func (p RetryPolicy) backoff(retry int) time.Duration {
+    initial := p.InitialBackoff
+    if initial <= 0 {
+        initial = DefaultRetryInitialBackoff
+    }
+
+    max := p.MaxBackoff
+    if max <= 0 {
+        max = DefaultRetryMaxBackoff
+    }
+
+    delay := max
+    if retry - 1 < 32 {
+        delay = initial << uint(retry - 1)
+        if delay <= 0 || delay > max {
+            delay = max
+        }
+    }
+
+    return time.Duration(rand.Int63n(int64(delay)) + 1)
+}
+
+func (p RetryPolicy) isRetryableStatus(status int) bool {
+    statuses := p.RetryOnStatus
+    if statuses == nil {
+        statuses = DefaultRetryOnStatus
+    }
+
+    for _, s := range statuses {
+        if s == status {
+            return true
+        }
+    }
+
+    return false
+}
+
+// attemptsFor returns how many times a request with the given method may be sent.
+// Reads are retried by default, writes only when RetryWrites is set
+func (p RetryPolicy) attemptsFor(method Method) int {
+    if method == MethodGet || method == MethodHead || p.RetryWrites {
+        return p.maxAttempts()
+    }
+
+    return 1
+}
+
+func (c *Client) getRetryPolicy() RetryPolicy {
+    c.mu.RLock()
+    defer c.mu.RUnlock()
+
+    return c.config.Retry
+}
+
+func sleepCtx(ctx context.Context, method Method, endpoint string, delay time.Duration) error {
+    timer := time.NewTimer(delay)
+    defer timer.Stop()
+
+    select {
+    case <-ctx.Done():
+        return &ContextError{method, endpoint, ctx.Err()}
+    case <-timer.C:
+        return nil
+    }
+}
+
+func bulkItemStatus(item interface{}) int {
+    actions, ok := item.(map[string]interface{}); if !ok {
+        return 0
+    }
+
+    for _, resp := range actions {
+        resp, ok := resp.(map[string]interface{}); if !ok {
+            continue
+        }
+
+        status, ok := resp["status"].(json.Number); if !ok {
+            continue
+        }
+
+        s, err := status.Int64(); if err == nil {
+            return int(s)
+        }
+    }
+
+    return 0
+}
+
+// resendRejected resends the bulk items rejected with 429 until they pass
+// or the retry policy gives up. stmts are the bulk stmts in the order
+// of the response items, the new outcomes replace the rejected ones in result
+func (c *Client) resendRejected(ctx context.Context, result map[string]interface{}, stmts []string, waitToRefresh ...bool) (map[string]interface{}, error) {
+    policy := c.getRetryPolicy()
+    if !policy.RetryWrites {
+        return result, nil
+    }
+
+    items, ok := result["items"].([]interface{}); if !ok || len(items) != len(stmts) {
+        return result, nil
+    }
+
+    endpoint := "/_bulk"
+    for retry := 1; retry < policy.maxAttempts(); retry++ {
+        var positions []int
+        body := ""
+        for pos, item := range items {
+            if bulkItemStatus(item) == 429 {
+                positions = append(positions, pos)
+                body += stmts[pos]
+            }
+        }
+
+        if len(positions) == 0 {
+            break
+        }
+
+        err := sleepCtx(ctx, MethodPost, endpoint, policy.backoff(retry))
+        if err != nil {
+            return result, err
+        }
+
+        // a single request per round, the rounds themselves are the retries
+        response, _, err := c.perform(ctx, MethodPost, requestPath(endpoint, waitToRefresh...), body)
+        if err != nil {
+            return result, err
+        }
+
+        retried, _ := response.(map[string]interface{})
+        retriedItems, ok := retried["items"].([]interface{}); if !ok || len(retriedItems) != len(positions) {
+            break
+        }
+
+        for k, pos := range positions {
+            items[pos] = retriedItems[k]
+        }
+    }
+
+    return result, nil
+}
+
+// Retryable reports whether the item failed with a rejection( 429 ) or a version conflict
+func (r SetItemResult) Retryable() bool {
+    if r.Err == nil {
+        return false
+    }
+
+    return r.Status == 429 || errors.Is(r.Err, ErrVersionConflict)
+}
+
+// RetryParams returns the entities of params whose items failed with a retryable error.
+// params must be the SetParams the result was returned for
+func (r SetResult) RetryParams(params SetParams) SetParams {
+    var retry SetParams
+    for _, item := range r.Items {
+        if !item.Retryable() {
+            continue
+        }
+
+        entities := params.entities(item.param)
+        if item.Position < 0 || item.Position >= len(entities) {
+            continue
+        }
+
+        retry.add(item.param, entities[item.Position])
+    }
+
+    return retry
+}
+
+func (p *SetParams) slice(param string) *[]map[string]interface{} {
+    switch param {
+    case "ToAdd":
+        return &p.ToAdd
+    case "ToUpdate":
+        return &p.ToUpdate
+    case "ToDelete":
+        return &p.ToDelete
+    case "ToIndex":
+        return &p.ToIndex
+    case "ToUpsert":
+        return &p.ToUpsert
+    }
+
+    return nil
+}
+
+func (p SetParams) entities(param string) []map[string]interface{} {
+    slice := p.slice(param)
+    if slice == nil {
+        return nil
+    }
+
+    return *slice
+}
+
+func (p *SetParams) add(param string, entity map[string]interface{}) {
+    slice := p.slice(param)
+    if slice != nil {
+        *slice = append(*slice, entity)
+    }
+}
@@ -0,0 +1,52 @@
+package elastic
+
+import (
+    "errors"
+    "fmt"
+)
+
+// Script is an update script, either inline Source or the ID of a stored script
+type Script struct {
+    Source string
+    ID string
+    // Lang defaults to painless
+    Lang string
+    Params map[string]interface{}
+}
+
+func (s Script) toMap() (map[string]interface{}, error) {
+    if (s.Source == "") == (s.ID == "") {
+        return nil, errors.New(fmt.Sprintf("Script needs either Source or ID: %+v", s))
+    }
+
+    res := map[string]interface{}{}
+    if s.Source != "" {
+        res["source"] = s.Source
+    } else {
+        res["id"] = s.ID
+    }
+    if s.Lang != "" {
+        res["lang"] = s.Lang
+    }
+    if len(s.Params) > 0 {
+        res["params"] = s.Params
+    }
+
+    return res, nil
+}
+
+// scriptOf accepts a Script, *Script or a raw script map
+func scriptOf(value interface{}) (map[string]interface{}, error) {
+    switch script := value.(type) {
+    case Script:
+        return script.toMap()
+    case *Script:
+        if script != nil {
+            return script.toMap()
+        }
+    case map[string]interface{}:
+        return script, nil
+    }
+
+    return nil, errors.New(fmt.Sprintf("Unknown elastic script: %v", value))
+}
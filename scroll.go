@@ -0,0 +1,139 @@
+package elastic
+
+import (
+    "errors"
+    "fmt"
+    "io"
+    "net/url"
+)
+
+// ScrollIterator pages through every document matched by a query with the scroll API.
+// It is not safe for concurrent use
+type ScrollIterator struct {
+    doc *doc
+    query map[string]interface{}
+    indexName string
+    keepAlive string
+
+    scrollId string
+    done bool
+}
+
+// Scroll returns an iterator over all the documents matched by query, pageSize hits at a time.
+// keepAlive( e.g. "1m" ) defaults to DefaultScrollKeepAlive, pageSize <= 0 keeps the query "size"
+func (i *doc) Scroll(query map[string]interface{}, indexName string, keepAlive string, pageSize int) *ScrollIterator {
+    if keepAlive == "" {
+        keepAlive = DefaultScrollKeepAlive
+    }
+
+    // copied, so the caller's query is left as it is
+    body := make(map[string]interface{}, len(query) + 1)
+    for k, v := range query {
+        body[k] = v
+    }
+    if pageSize > 0 {
+        body["size"] = pageSize
+    }
+
+    return &ScrollIterator{
+        doc: i,
+        query: body,
+        indexName: indexName,
+        keepAlive: keepAlive,
+    }
+}
+
+// Next returns the next page, io.EOF once every document has been returned.
+// The scroll context is cleared as soon as the last page is reached
+func (s *ScrollIterator) Next() (SearchResult, error) {
+    if s.done {
+        return SearchResult{}, io.EOF
+    }
+
+    if err := s.doc.ctx.Err(); err != nil {
+        return SearchResult{}, &ContextError{MethodPost, "/_search/scroll", err}
+    }
+
+    var result map[string]interface{}
+    var err error
+    if s.scrollId == "" {
+        result, err = s.first()
+    } else {
+        result, err = s.next()
+    }
+    if err != nil {
+        return SearchResult{}, fmt.Errorf("Failed to scroll elastic index: %w", err)
+    }
+
+    page, err := parseSearchResult(result)
+    if err != nil {
+        return SearchResult{}, err
+    }
+
+    if scrollId, ok := result["_scroll_id"].(string); ok {
+        s.scrollId = scrollId
+    }
+
+    if len(page.Hits) == 0 {
+        err = s.Close()
+        if err != nil {
+            return SearchResult{}, err
+        }
+
+        return SearchResult{}, io.EOF
+    }
+
+    return page, nil
+}
+
+func (s *ScrollIterator) first() (map[string]interface{}, error) {
+    queryJson, err := toJson(s.query)
+    if err != nil {
+        return nil, errors.New(fmt.Sprintf("Failed to json elastic query: %v", err))
+    }
+
+    endpoint := "/"+s.indexName+"/_search?scroll="+url.QueryEscape(s.keepAlive)
+
+    return s.doc.client.RequestCtx(s.doc.ctx, MethodPost, endpoint, queryJson)
+}
+
+func (s *ScrollIterator) next() (map[string]interface{}, error) {
+    params, err := toJson(map[string]interface{}{
+        "scroll": s.keepAlive,
+        "scroll_id": s.scrollId,
+    })
+    if err != nil {
+        return nil, errors.New(fmt.Sprintf("Failed to json elastic scroll: %v", err))
+    }
+
+    return s.doc.client.RequestCtx(s.doc.ctx, MethodPost, "/_search/scroll", params)
+}
+
+// Close clears the scroll context, it is safe to call more than once.
+// With a canceled context the scroll is left to expire after keepAlive
+func (s *ScrollIterator) Close() error {
+    if s.done {
+        return nil
+    }
+    s.done = true
+
+    // nothing to clear, or no way to send it
+    if s.scrollId == "" || s.doc.ctx.Err() != nil {
+        return nil
+    }
+
+    params, err := toJson(map[string]interface{}{
+        "scroll_id": []string{s.scrollId},
+    })
+    if err != nil {
+        return errors.New(fmt.Sprintf("Failed to json elastic scroll: %v", err))
+    }
+
+    _, err = s.doc.client.RequestCtx(s.doc.ctx, MethodDelete, "/_search/scroll", params)
+    // an expired scroll is already cleared
+    if err != nil && !errors.Is(err, ErrNotFound) {
+        return fmt.Errorf("Failed to clear elastic scroll: %w", err)
+    }
+
+    return nil
+}
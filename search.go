@@ -0,0 +1,136 @@
+package elastic
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+)
+
+// parseSearchResult converts a decoded _search response into SearchResult
+func parseSearchResult(result map[string]interface{}) (SearchResult, error) {
+    var res SearchResult
+
+    hits, ok := result["hits"].(map[string]interface{}); if !ok {
+        return res, errors.New(fmt.Sprintf("Failed to parse elastic result: %v", result))
+    }
+
+    res.Took = toInt(result["took"])
+    res.TimedOut, _ = result["timed_out"].(bool)
+
+    if shards, ok := result["_shards"].(map[string]interface{}); ok {
+        res.Shards = ShardsInfo{
+            Total: toInt(shards["total"]),
+            Successful: toInt(shards["successful"]),
+            Skipped: toInt(shards["skipped"]),
+            Failed: toInt(shards["failed"]),
+        }
+    }
+
+    res.Aggregations, _ = result["aggregations"].(map[string]interface{})
+    res.Aggs = Aggregations(res.Aggregations)
+
+    innerHits, err := parseHits(hits)
+    if err != nil {
+        return res, err
+    }
+
+    res.Total = innerHits.Total
+    res.MaxScore = innerHits.MaxScore
+    res.Hits = innerHits.Hits
+
+    return res, nil
+}
+
+// parseHits parses the "hits" object, which has the same shape
+// for the top level result and for inner_hits
+func parseHits(hits map[string]interface{}) (InnerHits, error) {
+    var res InnerHits
+
+    switch total := hits["total"].(type) {
+    case map[string]interface{}:
+        res.Total.Value = toInt(total["value"])
+        res.Total.Relation, _ = total["relation"].(string)
+    case json.Number:
+        // rest_total_hits_as_int=true
+        res.Total.Value = toInt(total)
+        res.Total.Relation = "eq"
+    }
+
+    res.MaxScore = toFloatPtr(hits["max_score"])
+
+    rawHits, _ := hits["hits"].([]interface{})
+    res.Hits = make([]Hit, 0, len(rawHits))
+    for _, item := range rawHits {
+        raw, ok := item.(map[string]interface{}); if !ok {
+            return res, errors.New(fmt.Sprintf("Failed to parse elastic hit: %v", item))
+        }
+
+        hit, err := parseHit(raw)
+        if err != nil {
+            return res, err
+        }
+
+        res.Hits = append(res.Hits, hit)
+    }
+
+    return res, nil
+}
+
+func parseHit(raw map[string]interface{}) (Hit, error) {
+    hit := Hit{raw: raw}
+
+    hit.Index, _ = raw["_index"].(string)
+    hit.ID, _ = raw["_id"].(string)
+    hit.Routing, _ = raw["_routing"].(string)
+    hit.Score = toFloatPtr(raw["_score"])
+    hit.Version = int64(toInt(raw["_version"]))
+    hit.SeqNo = int64(toInt(raw["_seq_no"]))
+    hit.PrimaryTerm = int64(toInt(raw["_primary_term"]))
+    hit.Source, _ = raw["_source"].(map[string]interface{})
+    hit.Fields, _ = raw["fields"].(map[string]interface{})
+    hit.Sort, _ = raw["sort"].([]interface{})
+
+    if highlight, ok := raw["highlight"].(map[string]interface{}); ok {
+        hit.Highlight = make(map[string][]string, len(highlight))
+        for field, fragments := range highlight {
+            list, _ := fragments.([]interface{})
+            for _, fragment := range list {
+                if str, ok := fragment.(string); ok {
+                    hit.Highlight[field] = append(hit.Highlight[field], str)
+                }
+            }
+        }
+    }
+
+    if innerHits, ok := raw["inner_hits"].(map[string]interface{}); ok {
+        hit.InnerHits = make(map[string]InnerHits, len(innerHits))
+        for name, inner := range innerHits {
+            innerMap, _ := inner.(map[string]interface{})
+            hits, ok := innerMap["hits"].(map[string]interface{}); if !ok {
+                return hit, errors.New(fmt.Sprintf("Failed to parse elastic inner hits %s: %v", name, inner))
+            }
+
+            parsed, err := parseHits(hits)
+            if err != nil {
+                return hit, err
+            }
+
+            hit.InnerHits[name] = parsed
+        }
+    }
+
+    return hit, nil
+}
+
+func toFloatPtr(value interface{}) *float64 {
+    number, ok := value.(json.Number); if !ok {
+        return nil
+    }
+
+    n, err := number.Float64()
+    if err != nil {
+        return nil
+    }
+
+    return &n
+}
@@ -0,0 +1,91 @@
+package elastic
+
+import (
+    "crypto/tls"
+    "crypto/x509"
+    "errors"
+    "fmt"
+    "io/ioutil"
+    "net/http"
+)
+
+func hasTlsConfig(config Config) bool {
+    return len(config.CACertPEM) > 0 || config.CACertFile != "" ||
+        len(config.ClientCertPEM) > 0 || config.ClientCertFile != "" ||
+        len(config.ClientKeyPEM) > 0 || config.ClientKeyFile != "" ||
+        config.InsecureSkipVerify
+}
+
+func readPEM(pem []byte, file string) ([]byte, error) {
+    if len(pem) > 0 || file == "" {
+        return pem, nil
+    }
+
+    return ioutil.ReadFile(file)
+}
+
+func newTlsConfig(config Config) (*tls.Config, error) {
+    tlsConfig := &tls.Config{
+        InsecureSkipVerify: config.InsecureSkipVerify,
+    }
+
+    caCert, err := readPEM(config.CACertPEM, config.CACertFile)
+    if err != nil {
+        return nil, fmt.Errorf("Failed to read elastic CA certificate: %w", err)
+    }
+
+    if len(caCert) > 0 {
+        pool := x509.NewCertPool()
+        if !pool.AppendCertsFromPEM(caCert) {
+            return nil, errors.New("No valid certificates found in elastic CA certificate")
+        }
+
+        tlsConfig.RootCAs = pool
+    }
+
+    clientCert, err := readPEM(config.ClientCertPEM, config.ClientCertFile)
+    if err != nil {
+        return nil, fmt.Errorf("Failed to read elastic client certificate: %w", err)
+    }
+
+    clientKey, err := readPEM(config.ClientKeyPEM, config.ClientKeyFile)
+    if err != nil {
+        return nil, fmt.Errorf("Failed to read elastic client key: %w", err)
+    }
+
+    if len(clientCert) > 0 || len(clientKey) > 0 {
+        cert, err := tls.X509KeyPair(clientCert, clientKey)
+        if err != nil {
+            return nil, fmt.Errorf("Failed to load elastic client certificate: %w", err)
+        }
+
+        tlsConfig.Certificates = []tls.Certificate{cert}
+    }
+
+    return tlsConfig, nil
+}
+
+// newTlsTransport clones base( http.DefaultTransport when nil ) with the TLS settings of config
+func newTlsTransport(config Config, base http.RoundTripper) (http.RoundTripper, error) {
+    if base == nil {
+        base = http.DefaultTransport
+    }
+
+    if !hasTlsConfig(config) {
+        return base, nil
+    }
+
+    transport, ok := base.(*http.Transport); if !ok {
+        return nil, errors.New("Elastic TLS settings require Transport to be *http.Transport")
+    }
+
+    tlsConfig, err := newTlsConfig(config)
+    if err != nil {
+        return nil, err
+    }
+
+    transport = transport.Clone()
+    transport.TLSClientConfig = tlsConfig
+
+    return transport, nil
+}
@@ -1,5 +1,11 @@
 package elastic
 
+import (
+    "context"
+    "net/http"
+    "sync"
+    "time"
+)
 
 type Action string
 func (a Action) String() string {
@@ -14,14 +20,137 @@ func (m Method) String() string {
 type Config struct {
     Host string
     Port int
+    // User/Password are sent as Basic auth
     User string
     Password string
+    // APIKey is the base64 encoded "id:api_key" pair sent with the ApiKey scheme
+    APIKey string
+    // BearerToken is an OAuth2/JWT token sent with the Bearer scheme
+    BearerToken string
+    // ServiceToken is a service account token, also sent with the Bearer scheme
+    ServiceToken string
+    // CredentialsProvider returns the whole Authorization header value.
+    // It is called for every request, so rotated tokens are picked up without Init
+    CredentialsProvider func(ctx context.Context) (string, error)
+
+    // Scheme is "http" or "https", DefaultScheme when empty
+    Scheme string
+
+    // CACertPEM( or CACertFile ) replaces the system roots when set
+    CACertPEM []byte
+    CACertFile string
+    // ClientCertPEM/ClientKeyPEM( or the *File variants ) are sent for mutual TLS
+    ClientCertPEM []byte
+    ClientKeyPEM []byte
+    ClientCertFile string
+    ClientKeyFile string
+    // InsecureSkipVerify disables server certificate verification
+    InsecureSkipVerify bool
+
+    // HTTPClient is used for every request when set
+    HTTPClient *http.Client
+    // Transport is wrapped into a new http.Client when HTTPClient is not set
+    Transport http.RoundTripper
+
+    // Nodes are extra "host[:port]" addresses, requests are spread round-robin
+    // over Host and Nodes
+    Nodes []string
+    // DeadNodeBackoff is how long a failed node stays out of rotation,
+    // doubled with every consecutive failure. DefaultDeadNodeBackoff when zero
+    DeadNodeBackoff time.Duration
+    // DiscoverNodes replaces the node list from /_nodes/http on init
+    DiscoverNodes bool
+
+    // Retry is applied to network errors and RetryOnStatus responses
+    Retry RetryPolicy
+}
+
+// RetryPolicy retries reads( GET, HEAD ) by default and writes only
+// when RetryWrites is set. Zero values fall back to the Default* settings
+type RetryPolicy struct {
+    // MaxAttempts counts the first request too, 1 disables retries
+    MaxAttempts int
+    InitialBackoff time.Duration
+    MaxBackoff time.Duration
+    // RetryOnStatus defaults to DefaultRetryOnStatus when nil
+    RetryOnStatus []int
+    // RetryWrites enables retries of POST/PUT/DELETE and resending
+    // bulk items rejected with 429 in Set
+    RetryWrites bool
 }
 
+// Client holds the connection settings of a single cluster.
+// config, nodes and httpClient are guarded by mu
+type Client struct {
+    mu sync.RWMutex
+
+    config Config
+    nodes []*node
+    nodeIdx int
+    httpClient *http.Client
+}
+
+// WriteOptions make a single document write conditional and routed
+type WriteOptions struct {
+    // IfSeqNo and IfPrimaryTerm are sent when IfPrimaryTerm is set. The write
+    // fails with ErrVersionConflict when the document changed since it was read
+    IfSeqNo int64
+    IfPrimaryTerm int64
+    // Version is sent when VersionType( "external" or "external_gte" ) is set
+    Version int64
+    VersionType string
+    Routing string
+    WaitToRefresh bool
+}
+
+// WriteResult is the outcome of a single document write
+type WriteResult struct {
+    Index string
+    ID string
+    // Result is "created", "updated", "deleted" or "noop"
+    Result string
+    Version int64
+    SeqNo int64
+    PrimaryTerm int64
+}
+
+// PatchOptions configure Patch, the update itself is described by the entity
+type PatchOptions struct {
+    // IfSeqNo and IfPrimaryTerm are sent when IfPrimaryTerm is set, see WriteOptions
+    IfSeqNo int64
+    IfPrimaryTerm int64
+    // RetryOnConflict retries the update that many times on a version conflict
+    RetryOnConflict int
+    Routing string
+    // DocAsUpsert creates the document from the entity fields when it doesn't exist
+    DocAsUpsert bool
+    // ReturnSource returns the updated _source, setting SourceIncludes
+    // or SourceExcludes returns it filtered
+    ReturnSource bool
+    SourceIncludes []string
+    SourceExcludes []string
+    // DisableDetectNoop writes the document even when the update doesn't change it
+    DisableDetectNoop bool
+    WaitToRefresh bool
+}
+
+// PatchResult is the outcome of Patch, Source is set when it was requested
+type PatchResult struct {
+    WriteResult
+    Source map[string]interface{}
+}
+
+// SetParams are the entities of a bulk Set. Besides _id, entities may carry the per-item
+// meta keys _routing, _if_seq_no, _if_primary_term, _retry_on_conflict( updates ) and _pipeline( creates ).
+// Updates may set _script( a Script ) instead of document fields, and _upsert
 type SetParams struct {
     ToAdd []map[string]interface{}
     ToUpdate []map[string]interface{}
     ToDelete []map[string]interface{}
+    // ToIndex creates or fully replaces the entities, _id is generated when missing
+    ToIndex []map[string]interface{}
+    // ToUpsert updates the entities, creating the missing ones from the same fields
+    ToUpsert []map[string]interface{}
 }
 
 type SetResult struct {
@@ -30,6 +159,156 @@ type SetResult struct {
     Deleted int
     Failed  int
     Errors  []error
+    // Items are the results of every stmt, in the order they were sent
+    Items   []SetItemResult
+}
+
+// SetItemResult is the result of a single Set item
+type SetItemResult struct {
+    Action Action
+    Index string
+    ID string
+    Status int
+    // Result is "created", "updated", "deleted", "noop" or "not_found"
+    Result string
+    Version int64
+    SeqNo int64
+    PrimaryTerm int64
+    ErrorType string
+    ErrorReason string
+    // Err is set when the item failed
+    Err error
+    // Position is the index of the entity in its SetParams slice, or within
+    // the batch for BulkProcessor. -1 when the item couldn't be parsed
+    Position int
+
+    // param is the SetParams slice of the entity, empty for BulkProcessor
+    param string
+}
+
+// MGetOptions are applied to every document of MGet and MGetItems
+type MGetOptions struct {
+    // SourceIncludes/SourceExcludes filter the returned _source fields
+    SourceIncludes []string
+    SourceExcludes []string
+    // Routing is used for documents without their own routing
+    Routing string
+}
+
+// MGetItem is a single document of MGetItems
+type MGetItem struct {
+    ID string
+    // Index falls back to the indexName of MGetItems
+    Index string
+    Routing string
+}
+
+// GetOptions configure GetDocument
+type GetOptions struct {
+    Routing string
+    Preference string
+    // Realtime defaults to true on elastic, set it to false to read only refreshed data
+    Realtime *bool
+    // Refresh refreshes the shard before reading the document
+    Refresh bool
+    // SourceIncludes/SourceExcludes filter the returned _source fields
+    SourceIncludes []string
+    SourceExcludes []string
+    // StoredFields are returned in Document.Fields
+    StoredFields []string
+    // Version is sent when set, the read fails with ErrVersionConflict when the document has another version
+    Version int64
+    VersionType string
+}
+
+// Document is a single document with its metadata
+type Document struct {
+    Index string
+    ID string
+    Version int64
+    SeqNo int64
+    PrimaryTerm int64
+    Routing string
+    // Source is nil when _source is disabled or filtered out completely
+    Source map[string]interface{}
+    // Fields are the requested stored fields
+    Fields map[string]interface{}
+
+    raw map[string]interface{}
+}
+
+// MGetResult is a single document of MGet. Found is false for missing documents,
+// Err is set when the document couldn't be read, e.g. its index doesn't exist
+type MGetResult struct {
+    Document
+    Found bool
+    Err error
+}
+
+// SearchResult is a parsed _search response
+type SearchResult struct {
+    Took int
+    TimedOut bool
+    Shards ShardsInfo
+    Total Total
+    MaxScore *float64
+    Hits []Hit
+    // Aggregations is the raw "aggregations" object of the response
+    Aggregations map[string]interface{}
+    // Aggs is the same object with typed accessors
+    Aggs Aggregations
+}
+
+// SearchRequest is a single search of MSearch
+type SearchRequest struct {
+    Index string
+    Query map[string]interface{}
+    // Routing and Preference are optional
+    Routing string
+    Preference string
+}
+
+// MSearchResult is the result of a single MSearch request, Err is set when it failed
+type MSearchResult struct {
+    Result SearchResult
+    Err error
+}
+
+type ShardsInfo struct {
+    Total int
+    Successful int
+    Skipped int
+    Failed int
+}
+
+// Total is the number of matching documents, Relation is "eq"
+// or "gte" when the count is a lower bound
+type Total struct {
+    Value int
+    Relation string
+}
+
+type Hit struct {
+    Index string
+    ID string
+    Routing string
+    Score *float64
+    Version int64
+    SeqNo int64
+    PrimaryTerm int64
+    Source map[string]interface{}
+    Fields map[string]interface{}
+    Highlight map[string][]string
+    Sort []interface{}
+    InnerHits map[string]InnerHits
+
+    raw map[string]interface{}
+}
+
+type InnerHits struct {
+    Total Total
+    MaxScore *float64
+    Hits []Hit
 }
 
 type Indice struct {
@@ -1,10 +1,15 @@
 package elastic
 
+import (
+    "time"
+)
+
 
 const (
     ActionCreate Action = "create"
     ActionUpdate Action = "update"
     ActionDelete Action = "delete"
+    ActionIndex Action = "index"
 )
 
 const (
@@ -15,14 +20,24 @@ const (
     MethodDelete Method = "DELETE"
 )
 
-const DateFormatElastic = "2006-01-02T15:04:05"
-const DateFormat = "2006-01-02 15:04:05"
+const DefaultScheme = "https"
+
+const DefaultDeadNodeBackoff = 30 * time.Second
+const maxDeadNodeBackoffShift = 5
 
-var elasticConfig Config
-var elasticUrl string
+const DefaultRetryMaxAttempts = 3
+const DefaultRetryInitialBackoff = 100 * time.Millisecond
+const DefaultRetryMaxBackoff = 5 * time.Second
 
-var lastQuery string
+var DefaultRetryOnStatus = []int{429, 502, 503, 504}
+
+const DefaultScrollKeepAlive = "1m"
+
+const DefaultBulkActions = 1000
+const DefaultBulkSize = 5 << 20
+
+const DateFormatElastic = "2006-01-02T15:04:05"
+const DateFormat = "2006-01-02 15:04:05"
 
-var docs *doc
-var indexes *index
+var defaultClient = &Client{}
 